@@ -0,0 +1,124 @@
+package matcher
+
+import (
+	"fmt"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"golang.org/x/text/width"
+)
+
+type ColumnUnit string
+
+const (
+	// ColumnRune counts runes, as yaml.v3 does.
+	ColumnRune ColumnUnit = "rune"
+	// ColumnByte counts bytes of UTF-8, as Vim col() does.
+	ColumnByte ColumnUnit = "byte"
+	// ColumnUTF16 counts UTF-16 code units, as Language Server Protocol does.
+	ColumnUTF16 ColumnUnit = "utf16"
+	// ColumnVisual counts display width with expanding tabs, as Emacs
+	// current-column does.
+	ColumnVisual ColumnUnit = "visual"
+)
+
+// Column is a way to count columns in a line.
+type Column struct {
+	Unit ColumnUnit
+	// Base is the column of the beginning of the line, 0 or 1.
+	Base int
+	// TabWidth is used with ColumnVisual.
+	TabWidth int
+}
+
+// DefaultColumn is 1-based rune column which yaml.v3 reports.
+var DefaultColumn = Column{
+	Unit: ColumnRune,
+	Base: 1,
+}
+
+func (c Column) Validate() error {
+	switch c.Unit {
+	case ColumnRune, ColumnByte, ColumnUTF16:
+	case ColumnVisual:
+		if c.TabWidth <= 0 {
+			return fmt.Errorf("tab width must be positive: %d", c.TabWidth)
+		}
+	default:
+		return fmt.Errorf("unsupported column unit: %s", c.Unit)
+	}
+	if c.Base != 0 && c.Base != 1 {
+		return fmt.Errorf("column base must be 0 or 1: %d", c.Base)
+	}
+	return nil
+}
+
+// RuneColumn converts the column in the line into 1-based rune column. A
+// column pointing the middle of a rune is converted into the column of the
+// rune, and a column after the end of the line counts one per rune.
+func (c Column) RuneColumn(text string, col int) int {
+	rest := col - c.Base
+	idx := 0
+	visual := 0
+	for _, r := range text {
+		w := c.width(r, visual)
+		visual += w
+		if rest < w {
+			return idx + 1
+		}
+		rest -= w
+		idx++
+	}
+	return idx + rest + 1
+}
+
+// FromRuneColumn converts 1-based rune column in the line into the column,
+// the inverse of RuneColumn. A column after the end of the line counts one per
+// rune.
+func (c Column) FromRuneColumn(text string, col int) int {
+	rest := col - 1
+	n := 0
+	for _, r := range text {
+		if rest <= 0 {
+			break
+		}
+		n += c.width(r, n)
+		rest--
+	}
+	return n + max(rest, 0) + c.Base
+}
+
+// Len returns the length of the text in the unit.
+func (c Column) Len(text string) int {
+	n := 0
+	for _, r := range text {
+		n += c.width(r, n)
+	}
+	return n
+}
+
+// width returns the length of the rune in the unit. visual is the visual
+// column the rune is at, used to expand tab.
+func (c Column) width(r rune, visual int) int {
+	switch c.Unit {
+	case ColumnByte:
+		return utf8.RuneLen(r)
+	case ColumnUTF16:
+		return utf16.RuneLen(r)
+	case ColumnVisual:
+		return visualWidth(r, visual, c.TabWidth)
+	default:
+		return 1
+	}
+}
+
+func visualWidth(r rune, visual, tabWidth int) int {
+	if r == '\t' {
+		return tabWidth - visual%tabWidth
+	}
+	switch width.LookupRune(r).Kind() {
+	case width.EastAsianWide, width.EastAsianFullwidth:
+		return 2
+	}
+	return 1
+}
@@ -2,15 +2,52 @@ package matcher
 
 import (
 	"fmt"
+	"unicode/utf8"
 
+	"github.com/gidoichi/yaml-path/domain/source"
 	yamlv3 "gopkg.in/yaml.v3"
 )
 
+// NodeMatcher matches a scalar or alias node by the position yaml.v3 reports.
 type NodeMatcher interface {
 	Match(node *yamlv3.Node) bool
 	String() string
 }
 
+// SpanNodeMatcher is a NodeMatcher which matches the range of the token
+// written in the source, including quotes, anchors and tags.
+type SpanNodeMatcher interface {
+	NodeMatcher
+	MatchSpan(node *yamlv3.Node, span source.Span) bool
+}
+
+// MatchSpan matches the node by the span if the matcher supports it, or by the
+// node only otherwise.
+func MatchSpan(m NodeMatcher, node *yamlv3.Node, span source.Span) bool {
+	if sm, ok := m.(SpanNodeMatcher); ok {
+		return sm.MatchSpan(node, span)
+	}
+	return m.Match(node)
+}
+
+// nodeSpan returns the range of the value of the node, which is all that is
+// known without the source.
+func nodeSpan(node *yamlv3.Node) source.Span {
+	return source.Span{
+		Start: source.Position{Line: node.Line, Column: node.Column},
+		End:   source.Position{Line: node.Line, Column: node.Column + utf8.RuneCountInString(node.Value)},
+	}
+}
+
+// SourceNodeMatcher is a NodeMatcher whose position depends on the text of
+// the source.
+type SourceNodeMatcher interface {
+	NodeMatcher
+	// Resolve returns the matcher whose position is in the coordinates of
+	// yaml.v3.
+	Resolve(src *source.Source) (NodeMatcher, error)
+}
+
 type NodeMatcherByLine struct {
 	line int
 }
@@ -19,6 +56,10 @@ func (m *NodeMatcherByLine) Match(node *yamlv3.Node) bool {
 	return node.Line == m.line
 }
 
+func (m *NodeMatcherByLine) MatchSpan(node *yamlv3.Node, span source.Span) bool {
+	return span.ContainsLine(m.line)
+}
+
 func (m *NodeMatcherByLine) String() string {
 	return fmt.Sprintf("{line: %d}", m.line)
 }
@@ -28,19 +69,142 @@ func NewNodeMatcherByLine(line int) *NodeMatcherByLine {
 }
 
 type NodeMatcherByLineAndCol struct {
-	line int
-	col  int
+	line   int
+	col    int
+	column Column
 }
 
 func (m *NodeMatcherByLineAndCol) Match(node *yamlv3.Node) bool {
-	return (node.Line == m.line) &&
-		(node.Column <= m.col) && (m.col < node.Column+len(node.Value))
+	return m.MatchSpan(node, nodeSpan(node))
+}
+
+func (m *NodeMatcherByLineAndCol) MatchSpan(node *yamlv3.Node, span source.Span) bool {
+	return span.Contains(source.Position{Line: m.line, Column: m.col})
+}
+
+// Resolve converts the column into the rune column of yaml.v3.
+func (m *NodeMatcherByLineAndCol) Resolve(src *source.Source) (NodeMatcher, error) {
+	if m.column == DefaultColumn {
+		return m, nil
+	}
+	if err := m.column.Validate(); err != nil {
+		return nil, err
+	}
+	text, _ := src.Line(m.line)
+	return NewNodeMatcherByLineAndCol(m.line, m.column.RuneColumn(text, m.col)), nil
 }
 
 func (m *NodeMatcherByLineAndCol) String() string {
-	return fmt.Sprintf("{line: %d, col: %d}", m.line, m.col)
+	if m.column == DefaultColumn {
+		return fmt.Sprintf("{line: %d, col: %d}", m.line, m.col)
+	}
+	return fmt.Sprintf("{line: %d, col: %d, unit: %s, base: %d}", m.line, m.col, m.column.Unit, m.column.Base)
 }
 
 func NewNodeMatcherByLineAndCol(line, col int) *NodeMatcherByLineAndCol {
-	return &NodeMatcherByLineAndCol{line: line, col: col}
+	return &NodeMatcherByLineAndCol{line: line, col: col, column: DefaultColumn}
+}
+
+// NewNodeMatcherByLineAndColIn returns a matcher of the column counted in the
+// way of column.
+func NewNodeMatcherByLineAndColIn(line, col int, column Column) *NodeMatcherByLineAndCol {
+	return &NodeMatcherByLineAndCol{line: line, col: col, column: column}
+}
+
+// NodeMatcherByOffset matches the token at the offset from the beginning of
+// the input stream. It has to be resolved against the source before matching.
+type NodeMatcherByOffset struct {
+	offset int
+	unit   ColumnUnit
+	base   int
+}
+
+func (m *NodeMatcherByOffset) Match(node *yamlv3.Node) bool {
+	return false
+}
+
+func (m *NodeMatcherByOffset) MatchSpan(node *yamlv3.Node, span source.Span) bool {
+	return false
+}
+
+// Resolve converts the offset into the line and the rune column. Line breaks
+// count as their length, that is 2 for CRLF, and so does a byte order mark at
+// the beginning of the source.
+func (m *NodeMatcherByOffset) Resolve(src *source.Source) (NodeMatcher, error) {
+	column := Column{Unit: m.unit, Base: 0}
+	if m.unit == ColumnVisual {
+		return nil, fmt.Errorf("unsupported offset unit: %s", m.unit)
+	}
+	if err := column.Validate(); err != nil {
+		return nil, err
+	}
+	if m.base != 0 && m.base != 1 {
+		return nil, fmt.Errorf("offset base must be 0 or 1: %d", m.base)
+	}
+
+	rest := m.offset - m.base
+	if rest >= 0 {
+		rest = max(rest-column.Len(src.BOM()), 0)
+	}
+	for line := 1; rest >= 0; line++ {
+		text, ok := src.Line(line)
+		if !ok {
+			break
+		}
+		n := column.Len(text)
+		eol := n + len(src.LineBreak(line))
+		if rest < eol {
+			return NewNodeMatcherByLineAndCol(line, column.RuneColumn(text, min(rest, n))), nil
+		}
+		rest -= eol
+	}
+	return nil, fmt.Errorf("offset out of range: %d", m.offset)
+}
+
+func (m *NodeMatcherByOffset) String() string {
+	return fmt.Sprintf("{offset: %d, unit: %s, base: %d}", m.offset, m.unit, m.base)
+}
+
+func NewNodeMatcherByOffset(offset int, unit ColumnUnit, base int) *NodeMatcherByOffset {
+	return &NodeMatcherByOffset{offset: offset, unit: unit, base: base}
+}
+
+// NodeMatcherByRange matches nodes containing both of the positions the start
+// and the end matchers indicate. Used with dyaml.Stream.PathEnclosingPoint, it
+// resolves the smallest node containing the range.
+type NodeMatcherByRange struct {
+	start NodeMatcher
+	end   NodeMatcher
+}
+
+func (m *NodeMatcherByRange) Match(node *yamlv3.Node) bool {
+	return m.start.Match(node) && m.end.Match(node)
+}
+
+func (m *NodeMatcherByRange) MatchSpan(node *yamlv3.Node, span source.Span) bool {
+	return MatchSpan(m.start, node, span) && MatchSpan(m.end, node, span)
+}
+
+func (m *NodeMatcherByRange) Resolve(src *source.Source) (NodeMatcher, error) {
+	start, end := m.start, m.end
+	var err error
+	if sm, ok := start.(SourceNodeMatcher); ok {
+		if start, err = sm.Resolve(src); err != nil {
+			return nil, err
+		}
+	}
+	if sm, ok := end.(SourceNodeMatcher); ok {
+		if end, err = sm.Resolve(src); err != nil {
+			return nil, err
+		}
+	}
+	return &NodeMatcherByRange{start: start, end: end}, nil
+}
+
+func (m *NodeMatcherByRange) String() string {
+	return fmt.Sprintf("{start: %s, end: %s}", m.start, m.end)
+}
+
+func NewNodeMatcherByRange(start, end NodeMatcher) *NodeMatcherByRange {
+	return &NodeMatcherByRange{start: start, end: end}
 }
@@ -0,0 +1,118 @@
+package source
+
+import (
+	"strings"
+)
+
+// Position is a position in the source. Line and Column are 1-based, and
+// Column counts runes as yaml.v3 does.
+type Position struct {
+	Line   int
+	Column int
+}
+
+func (p Position) Before(q Position) bool {
+	if p.Line != q.Line {
+		return p.Line < q.Line
+	}
+	return p.Column < q.Column
+}
+
+// Span is a range in the source. End is exclusive.
+type Span struct {
+	Start Position
+	End   Position
+}
+
+func (s Span) Contains(p Position) bool {
+	return !p.Before(s.Start) && p.Before(s.End)
+}
+
+// ContainsLine reports whether the span has any column of the line.
+func (s Span) ContainsLine(line int) bool {
+	last := s.End.Line
+	if s.End.Column <= 1 && s.Start.Line < last {
+		last--
+	}
+	return s.Start.Line <= line && line <= last
+}
+
+// bom is the byte order mark yaml.v3 skips without counting in columns.
+const bom = "\uFEFF"
+
+// Source is the raw input yaml is parsed from.
+type Source struct {
+	bom    string
+	lines  []string
+	breaks []string
+	// runes caches the lines converted into runes, see Runes.
+	runes map[int][]rune
+}
+
+// NewSource splits the data into lines. A leading byte order mark is removed
+// from the first line so that columns agree with yaml.v3.
+func NewSource(data []byte) *Source {
+	text, found := strings.CutPrefix(string(data), bom)
+	lines := strings.Split(text, "\n")
+	breaks := make([]string, len(lines))
+	for i, line := range lines {
+		if i == len(lines)-1 {
+			break
+		}
+		if trimmed, found := strings.CutSuffix(line, "\r"); found {
+			lines[i] = trimmed
+			breaks[i] = "\r\n"
+		} else {
+			breaks[i] = "\n"
+		}
+	}
+	src := &Source{lines: lines, breaks: breaks, runes: map[int][]rune{}}
+	if found {
+		src.bom = bom
+	}
+	return src
+}
+
+// BOM returns the byte order mark removed from the beginning of the data, or
+// empty string if the data has no byte order mark.
+func (s *Source) BOM() string {
+	if s == nil {
+		return ""
+	}
+	return s.bom
+}
+
+// Line returns the text of the line without line break.
+func (s *Source) Line(line int) (text string, ok bool) {
+	if s == nil || line < 1 || len(s.lines) < line {
+		return "", false
+	}
+	return s.lines[line-1], true
+}
+
+// Runes returns the runes of the line without line break. The result is
+// cached and shared between callers, so it must not be modified.
+func (s *Source) Runes(line int) (runes []rune, ok bool) {
+	if s == nil {
+		return nil, false
+	}
+	if runes, ok := s.runes[line]; ok {
+		return runes, true
+	}
+	text, ok := s.Line(line)
+	if !ok {
+		return nil, false
+	}
+	runes = []rune(text)
+	s.runes[line] = runes
+	return runes, true
+}
+
+// LineBreak returns the line break the line ends with, or empty string for the
+// last line.
+func (s *Source) LineBreak(line int) string {
+	if s == nil || line < 1 || len(s.breaks) < line {
+		return ""
+	}
+	return s.breaks[line-1]
+}
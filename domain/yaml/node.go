@@ -7,7 +7,8 @@ import (
 type Node yamlv3.Node
 
 const (
-	intTag = "!!int"
+	intTag   = "!!int"
+	mergeTag = "!!merge"
 )
 
 func (n *Node) FindChildValueByKey(key string) string {
@@ -54,3 +55,33 @@ func (n *Node) FindSequenceSelectionByMappingKey(idx int, key string) string {
 
 	return value
 }
+
+// FindSequenceIndexByMappingKey returns the index of the only sequence item
+// having the value for the key, or -1 if no such item is found.
+func (n *Node) FindSequenceIndexByMappingKey(key, value string) int {
+	if n.Kind != yamlv3.SequenceNode || value == "" {
+		return -1
+	}
+
+	idx := -1
+	for i, child := range n.Content {
+		if (*Node)(child).FindChildValueByKey(key) != value {
+			continue
+		}
+		if idx >= 0 {
+			return -1
+		}
+		idx = i
+	}
+
+	return idx
+}
+
+func (n *Node) findValueNode(key *yamlv3.Node) *yamlv3.Node {
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i] == key {
+			return n.Content[i+1]
+		}
+	}
+	return nil
+}
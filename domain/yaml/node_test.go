@@ -188,4 +188,58 @@ var _ = Describe("Node", func() {
 			})
 		})
 	})
+
+	Describe("FindSequenceIndexByMappingKey()", func() {
+		BeforeEach(func() {
+			node = dyaml.Node{
+				Kind: yamlv3.SequenceNode,
+				Content: []*yamlv3.Node{
+					{
+						Kind: yamlv3.MappingNode,
+						Content: []*yamlv3.Node{
+							{
+								Kind:  yamlv3.ScalarNode,
+								Value: "name",
+							},
+							{
+								Kind:  yamlv3.ScalarNode,
+								Value: "some",
+							},
+						},
+					},
+					{
+						Kind: yamlv3.MappingNode,
+						Content: []*yamlv3.Node{
+							{
+								Kind:  yamlv3.ScalarNode,
+								Value: "name",
+							},
+							{
+								Kind:  yamlv3.ScalarNode,
+								Value: "other",
+							},
+						},
+					},
+				},
+			}
+		})
+
+		Context("with the value of an item", func() {
+			It("should return the index of the item", func() {
+				Expect(node.FindSequenceIndexByMappingKey("name", "other")).To(Equal(1))
+			})
+		})
+
+		Context("with other key", func() {
+			It("should return -1", func() {
+				Expect(node.FindSequenceIndexByMappingKey("id", "other")).To(Equal(-1))
+			})
+		})
+
+		Context("with the value no item has", func() {
+			It("should return -1", func() {
+				Expect(node.FindSequenceIndexByMappingKey("name", "dummy")).To(Equal(-1))
+			})
+		})
+	})
 })
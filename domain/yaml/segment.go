@@ -0,0 +1,206 @@
+package yaml
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gidoichi/yaml-path/domain/source"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+type SegmentKind int
+
+const (
+	DocumentSegment SegmentKind = iota
+	KeySegment
+	IndexSegment
+	SelectorSegment
+)
+
+func (k SegmentKind) String() string {
+	switch k {
+	case DocumentSegment:
+		return "document"
+	case KeySegment:
+		return "key"
+	case IndexSegment:
+		return "index"
+	case SelectorSegment:
+		return "selector"
+	default:
+		return fmt.Sprintf("SegmentKind(%d)", int(k))
+	}
+}
+
+// Segment is an element of a path, which selects a node from the parent node.
+//
+// Segments taken from yaml documents have the position and the nodes they
+// indicate, and segments parsed from strings have only the selection.
+type Segment struct {
+	Kind SegmentKind
+	// Key is the mapping key for KeySegment, or the mapping key of sequence
+	// items for SelectorSegment.
+	Key string
+	// Value is the value of Key for SelectorSegment.
+	Value string
+	// Index is the index of the document for DocumentSegment, or the index of
+	// the sequence item for IndexSegment and SelectorSegment. -1 if unknown.
+	Index int
+
+	// Line and Column are the position of the document for DocumentSegment,
+	// the mapping key for KeySegment, and the sequence item for IndexSegment.
+	// Zero if unknown.
+	Line   int
+	Column int
+	// Span is the range of the mapping entry from the key for KeySegment, the
+	// sequence item from the dash for IndexSegment, and the content for
+	// DocumentSegment. ValueSpan is the range of Node. Zero if unknown.
+	Span      source.Span
+	ValueSpan source.Span
+	// Node is the node the segment selects.
+	Node *yamlv3.Node
+	// Parent is the node the segment selects from.
+	Parent *yamlv3.Node
+}
+
+// NewDocumentSegment returns a document segment of unknown document.
+func NewDocumentSegment() Segment {
+	return Segment{
+		Kind:  DocumentSegment,
+		Index: -1,
+	}
+}
+
+func NewKeySegment(key string) Segment {
+	return Segment{
+		Kind:  KeySegment,
+		Key:   key,
+		Index: -1,
+	}
+}
+
+func NewIndexSegment(idx int) Segment {
+	return Segment{
+		Kind:  IndexSegment,
+		Index: idx,
+	}
+}
+
+func NewSelectorSegment(key, value string) Segment {
+	return Segment{
+		Kind:  SelectorSegment,
+		Key:   key,
+		Value: value,
+		Index: -1,
+	}
+}
+
+// Selection returns the value for the key of the mapping this segment selects,
+// if the value is available to select the mapping uniquely from the sequence.
+func (s *Segment) Selection(key string) string {
+	switch s.Kind {
+	case IndexSegment:
+		if s.Parent == nil {
+			return ""
+		}
+		return (*Node)(s.Parent).FindSequenceSelectionByMappingKey(s.Index, key)
+	case SelectorSegment:
+		if s.Key != key {
+			return ""
+		}
+		return s.Value
+	}
+
+	return ""
+}
+
+func (s Segment) String() string {
+	switch s.Kind {
+	case DocumentSegment:
+		return ""
+	case KeySegment:
+		return s.Key
+	case IndexSegment:
+		return strconv.Itoa(s.Index)
+	case SelectorSegment:
+		return s.Key + "=" + s.Value
+	default:
+		return s.Kind.String()
+	}
+}
+
+type Segments []Segment
+
+func (s Segments) String() string {
+	var builder strings.Builder
+	for _, segment := range s {
+		if segment.Kind == DocumentSegment {
+			continue
+		}
+		builder.WriteString("/" + segment.String())
+	}
+	return builder.String()
+}
+
+// Segments converts the path into segments. The document index is unknown
+// because the path does not have it, see Stream.Segments.
+func (p Path) Segments() (segments Segments, err error) {
+	segments = Segments{}
+	for i := 0; i < p.Len(); i++ {
+		node := p[i]
+		switch node.Kind {
+		case yamlv3.DocumentNode:
+			segments = append(segments, Segment{
+				Kind:   DocumentSegment,
+				Index:  -1,
+				Line:   node.Line,
+				Column: node.Column,
+				Node:   node,
+			})
+		case yamlv3.SequenceNode:
+			i++
+			next, err := p.Get(i)
+			if err != nil {
+				return nil, fmt.Errorf("get node: %w", err)
+			}
+			seqidx, err := strconv.Atoi(next.Value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number: %w", err)
+			}
+			if seqidx < 0 || len(node.Content) <= seqidx {
+				return nil, fmt.Errorf("index out of range: %d", seqidx)
+			}
+			item := node.Content[seqidx]
+			segments = append(segments, Segment{
+				Kind:   IndexSegment,
+				Index:  seqidx,
+				Line:   item.Line,
+				Column: item.Column,
+				Node:   item,
+				Parent: node,
+			})
+		case yamlv3.MappingNode:
+			i++
+			next, err := p.Get(i)
+			if err != nil {
+				return nil, fmt.Errorf("get node: %w", err)
+			}
+			segments = append(segments, Segment{
+				Kind:   KeySegment,
+				Key:    next.Value,
+				Index:  -1,
+				Line:   next.Line,
+				Column: next.Column,
+				Node:   (*Node)(node).findValueNode((*yamlv3.Node)(next)),
+				Parent: node,
+			})
+		case yamlv3.ScalarNode, yamlv3.AliasNode:
+			continue
+		default:
+			return nil, fmt.Errorf("invalid path: %v", p)
+		}
+	}
+
+	return segments, nil
+}
@@ -0,0 +1,106 @@
+package yaml_test
+
+import (
+	"bytes"
+
+	dmatcher "github.com/gidoichi/yaml-path/domain/matcher"
+	"github.com/gidoichi/yaml-path/domain/source"
+	dyaml "github.com/gidoichi/yaml-path/domain/yaml"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+var _ = Describe("Segments", func() {
+	data := []byte(`first:
+  - document
+---
+top:
+  first:
+    - name: myname
+      attr2: val2
+`)
+	var yaml *dyaml.Stream
+
+	BeforeEach(func() {
+		var err error
+		yaml, err = dyaml.NewStream(bytes.NewReader(data))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	Describe("SegmentsAtPoint()", func() {
+		Context("indicating at mapping value node", func() {
+			matcher := dmatcher.NewNodeMatcherByLineAndCol(7, 14)
+
+			It("should return the segments with positions", func() {
+				segments, err := yaml.SegmentsAtPoint(matcher)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(segments).To(HaveLen(5))
+				Expect(segments[0].Kind).To(Equal(dyaml.DocumentSegment))
+				Expect(segments[0].Index).To(Equal(1))
+				Expect(segments[1].Kind).To(Equal(dyaml.KeySegment))
+				Expect(segments[1].Key).To(Equal("top"))
+				Expect([]int{segments[1].Line, segments[1].Column}).To(Equal([]int{4, 1}))
+				Expect(segments[2].Kind).To(Equal(dyaml.KeySegment))
+				Expect(segments[2].Key).To(Equal("first"))
+				Expect(segments[3].Kind).To(Equal(dyaml.IndexSegment))
+				Expect(segments[3].Index).To(Equal(0))
+				Expect([]int{segments[3].Line, segments[3].Column}).To(Equal([]int{6, 7}))
+				Expect(segments[3].Selection("name")).To(Equal("myname"))
+				Expect(segments[4].Kind).To(Equal(dyaml.KeySegment))
+				Expect(segments[4].Key).To(Equal("attr2"))
+				Expect(segments[4].Node.Value).To(Equal("val2"))
+			})
+
+			It("should return the segments with spans", func() {
+				segments, err := yaml.SegmentsAtPoint(matcher)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(segments[4].Span).To(Equal(span(7, 7, 7, 18)))
+				Expect(segments[4].ValueSpan).To(Equal(span(7, 14, 7, 18)))
+				Expect(segments[3].Span).To(Equal(span(6, 5, 7, 18)))
+				Expect(segments[3].ValueSpan).To(Equal(span(6, 7, 7, 18)))
+				Expect(segments[1].Span).To(Equal(span(4, 1, 7, 18)))
+				Expect(segments[1].ValueSpan).To(Equal(span(5, 3, 7, 18)))
+				Expect(segments[0].Span).To(Equal(span(4, 1, 7, 18)))
+			})
+		})
+	})
+
+	Describe("Path.Segments()", func() {
+		Context("with sequence node not followed by index", func() {
+			path := dyaml.Path{
+				&yamlv3.Node{
+					Kind: yamlv3.SequenceNode,
+				},
+			}
+
+			It("should return an error", func() {
+				_, err := path.Segments()
+
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+
+	Describe("Segments.String()", func() {
+		It("should join segments", func() {
+			segments := dyaml.Segments{
+				dyaml.NewDocumentSegment(),
+				dyaml.NewKeySegment("top"),
+				dyaml.NewSelectorSegment("name", "myname"),
+				dyaml.NewIndexSegment(0),
+			}
+
+			Expect(segments.String()).To(Equal("/top/name=myname/0"))
+		})
+	})
+})
+
+func span(startLine, startCol, endLine, endCol int) source.Span {
+	return source.Span{
+		Start: source.Position{Line: startLine, Column: startCol},
+		End:   source.Position{Line: endLine, Column: endCol},
+	}
+}
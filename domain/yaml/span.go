@@ -0,0 +1,371 @@
+package yaml
+
+import (
+	"unicode"
+
+	"github.com/gidoichi/yaml-path/domain/source"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// tokenSpan returns the range of the scalar or alias token as written in the
+// source, including its anchor and tag. Empty token spans a column so that it
+// can be pointed.
+func (y *Stream) tokenSpan(node *yamlv3.Node) source.Span {
+	start := source.Position{Line: node.Line, Column: node.Column}
+	s, ok := newScanner(y.source, start)
+	if !ok {
+		return source.Span{
+			Start: start,
+			End:   source.Position{Line: node.Line, Column: node.Column + max(len([]rune(node.Value)), 1)},
+		}
+	}
+
+	s.skipProperties(node)
+	switch {
+	case node.Kind == yamlv3.AliasNode:
+		s.skipAlias()
+	case node.Style&yamlv3.DoubleQuotedStyle != 0:
+		s.skipQuoted('"')
+	case node.Style&yamlv3.SingleQuotedStyle != 0:
+		s.skipQuoted('\'')
+	case node.Style&(yamlv3.LiteralStyle|yamlv3.FoldedStyle) != 0:
+		s.skipBlock()
+	default:
+		s.skipPlain(node.Value)
+	}
+
+	end := s.position()
+	if !start.Before(end) {
+		end = source.Position{Line: start.Line, Column: start.Column + 1}
+	}
+	return source.Span{Start: start, End: end}
+}
+
+// extent returns the range of the node as written in the source, from its
+// first token to the last token of its descendants.
+func (y *Stream) extent(node *yamlv3.Node) source.Span {
+	switch node.Kind {
+	case yamlv3.ScalarNode, yamlv3.AliasNode:
+		return y.tokenSpan(node)
+	case yamlv3.DocumentNode:
+		if len(node.Content) == 0 {
+			return y.tokenSpan(node)
+		}
+		return y.extent(node.Content[0])
+	}
+
+	start := source.Position{Line: node.Line, Column: node.Column}
+	end := start
+	if len(node.Content) > 0 {
+		end = y.extent(node.Content[len(node.Content)-1]).End
+	}
+	if node.Style&yamlv3.FlowStyle != 0 {
+		if s, ok := newScanner(y.source, end); ok {
+			if len(node.Content) == 0 {
+				s.skipProperties(node)
+				s.col++
+			}
+			s.skipFlowEnd()
+			end = s.position()
+		}
+	}
+	if !start.Before(end) {
+		end = source.Position{Line: start.Line, Column: start.Column + 1}
+	}
+	return source.Span{Start: start, End: end}
+}
+
+// pairSpan returns the range of the mapping entry from the key to the end of
+// the value.
+func (y *Stream) pairSpan(key, value *yamlv3.Node) source.Span {
+	return source.Span{
+		Start: y.tokenSpan(key).Start,
+		End:   y.extent(value).End,
+	}
+}
+
+// itemSpan returns the range of the sequence item, including the preceding
+// dash in block sequence.
+func (y *Stream) itemSpan(sequence, item *yamlv3.Node) source.Span {
+	span := y.extent(item)
+	if sequence.Style&yamlv3.FlowStyle != 0 {
+		return span
+	}
+
+	if runes, ok := y.source.Runes(item.Line); ok {
+		i := min(item.Column-2, len(runes)-1)
+		for 0 <= i && (runes[i] == ' ' || runes[i] == '\t') {
+			i--
+		}
+		if 0 <= i && runes[i] == '-' {
+			span.Start = source.Position{Line: item.Line, Column: i + 1}
+		}
+	}
+	return span
+}
+
+// pairRegion returns the range of the mapping entry, including trailing
+// spaces and comment in block mapping.
+func (y *Stream) pairRegion(mapping, key, value *yamlv3.Node) source.Span {
+	return y.toLineEnd(mapping, y.pairSpan(key, value))
+}
+
+// itemRegion returns the range of the sequence item, including trailing
+// spaces and comment in block sequence.
+func (y *Stream) itemRegion(sequence, item *yamlv3.Node) source.Span {
+	return y.toLineEnd(sequence, y.itemSpan(sequence, item))
+}
+
+// toLineEnd extends the span of the child of the block collection to the end
+// of the line.
+func (y *Stream) toLineEnd(collection *yamlv3.Node, span source.Span) source.Span {
+	if collection.Style&yamlv3.FlowStyle == 0 {
+		span.End = source.Position{Line: span.End.Line + 1, Column: 1}
+	}
+	return span
+}
+
+// scanner reads the source rune by rune from a position.
+type scanner struct {
+	source *source.Source
+	line   int
+	runes  []rune
+	// col is 0-based index of runes
+	col int
+}
+
+func newScanner(src *source.Source, pos source.Position) (*scanner, bool) {
+	if src == nil {
+		return nil, false
+	}
+	runes, ok := src.Runes(pos.Line)
+	if !ok {
+		return nil, false
+	}
+	if pos.Column < 1 || len(runes) < pos.Column-1 {
+		return nil, false
+	}
+	return &scanner{
+		source: src,
+		line:   pos.Line,
+		runes:  runes,
+		col:    pos.Column - 1,
+	}, true
+}
+
+func (s *scanner) position() source.Position {
+	return source.Position{Line: s.line, Column: s.col + 1}
+}
+
+func (s *scanner) eol() bool {
+	return len(s.runes) <= s.col
+}
+
+func (s *scanner) peek() rune {
+	if s.eol() {
+		return '\n'
+	}
+	return s.runes[s.col]
+}
+
+func (s *scanner) nextLine() bool {
+	runes, ok := s.source.Runes(s.line + 1)
+	if !ok {
+		return false
+	}
+	s.line++
+	s.runes = runes
+	s.col = 0
+	return true
+}
+
+func (s *scanner) skipSpaces() {
+	for !s.eol() && (s.peek() == ' ' || s.peek() == '\t') {
+		s.col++
+	}
+}
+
+// blankToEOL reports whether only blanks are left in the line.
+func (s *scanner) blankToEOL() bool {
+	for _, r := range s.runes[s.col:] {
+		if r != ' ' && r != '\t' {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *scanner) skipWord(flow bool) {
+	for !s.eol() {
+		r := s.peek()
+		if unicode.IsSpace(r) {
+			return
+		}
+		if flow && (r == ',' || r == '[' || r == ']' || r == '{' || r == '}') {
+			return
+		}
+		s.col++
+	}
+}
+
+// skipProperties skips anchor and tag preceding the content. The content may
+// start from the next line of the properties.
+func (s *scanner) skipProperties(node *yamlv3.Node) {
+	skipped := false
+	for r := s.peek(); r == '&' || r == '!'; r = s.peek() {
+		s.skipWord(r == '&')
+		s.skipSpaces()
+		skipped = true
+	}
+	if !skipped || !s.eol() {
+		return
+	}
+	if node.Value == "" && node.Style&(yamlv3.DoubleQuotedStyle|yamlv3.SingleQuotedStyle) == 0 {
+		return
+	}
+
+	end := *s
+	for s.nextLine() {
+		s.skipSpaces()
+		if !s.eol() && s.peek() != '#' {
+			return
+		}
+	}
+	*s = end
+}
+
+func (s *scanner) skipAlias() {
+	s.col++
+	s.skipWord(true)
+}
+
+func (s *scanner) skipQuoted(quote rune) {
+	s.col++
+	for {
+		if s.eol() {
+			if !s.nextLine() {
+				return
+			}
+			continue
+		}
+		switch r := s.peek(); {
+		case quote == '"' && r == '\\':
+			s.col += 2
+		case r == quote:
+			s.col++
+			if quote == '\'' && s.peek() == '\'' {
+				s.col++
+				continue
+			}
+			return
+		default:
+			s.col++
+		}
+	}
+}
+
+// skipFlowEnd skips to the end of the flow collection, over the separators
+// and comments after the last item.
+func (s *scanner) skipFlowEnd() {
+	for {
+		if s.eol() {
+			if !s.nextLine() {
+				return
+			}
+			continue
+		}
+		switch s.peek() {
+		case ']', '}':
+			s.col++
+			return
+		case '#':
+			s.col = len(s.runes)
+		default:
+			s.col++
+		}
+	}
+}
+
+// skipBlock skips the header and the content lines of literal or folded
+// scalar. The content ends before the first non-empty line not indented more
+// than the line the header is written.
+func (s *scanner) skipBlock() {
+	parent := indentOf(s.runes)
+	s.skipWord(false)
+
+	end := *s
+	indent := -1
+	for s.nextLine() {
+		n := indentOf(s.runes)
+		if n == len(s.runes) {
+			continue
+		}
+		if indent < 0 {
+			indent = n
+		}
+		if n <= parent || n < indent {
+			break
+		}
+		s.col = len(s.runes)
+		end = *s
+	}
+	*s = end
+}
+
+// skipPlain skips plain scalar by comparing with the value. The scalar may be
+// continued over several lines, whose line breaks are folded into a space or
+// newlines in the value.
+func (s *scanner) skipPlain(value string) {
+	rest := []rune(value)
+	for {
+		// trailing blanks are not a part of the value, even if the value
+		// continues with the space folded from the line break
+		for len(rest) > 0 && !s.eol() && s.peek() == rest[0] && !s.blankToEOL() {
+			rest = rest[1:]
+			s.col++
+		}
+		if len(rest) == 0 {
+			return
+		}
+
+		end := *s
+		s.skipSpaces()
+		if !s.eol() {
+			*s = end
+			return
+		}
+		// a line break is folded into a space, and empty lines into newlines
+		breaks := 1
+		switch rest[0] {
+		case ' ':
+			rest = rest[1:]
+		case '\n':
+			for len(rest) > 0 && rest[0] == '\n' {
+				rest = rest[1:]
+				breaks++
+			}
+		default:
+			*s = end
+			return
+		}
+		for range breaks {
+			if !s.nextLine() {
+				*s = end
+				return
+			}
+		}
+		s.skipSpaces()
+		if len(rest) == 0 || s.eol() || s.peek() != rest[0] {
+			*s = end
+			return
+		}
+	}
+}
+
+func indentOf(runes []rune) int {
+	n := 0
+	for n < len(runes) && runes[n] == ' ' {
+		n++
+	}
+	return n
+}
@@ -0,0 +1,117 @@
+package yaml_test
+
+import (
+	"bytes"
+
+	dmatcher "github.com/gidoichi/yaml-path/domain/matcher"
+	dyaml "github.com/gidoichi/yaml-path/domain/yaml"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Token span", func() {
+	data := []byte(`"double \"quoted\"": "multi
+  line"
+single: 'it''s'
+anchored: &anchor !!str tagged
+alias: *anchor
+empty:
+あい: うえ
+`)
+	var yaml *dyaml.Stream
+
+	BeforeEach(func() {
+		var err error
+		yaml, err = dyaml.NewStream(bytes.NewReader(data))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	DescribeTable("PathAtPoint() should resolve the token as written",
+		func(line, col int, key string) {
+			segments, err := yaml.SegmentsAtPoint(dmatcher.NewNodeMatcherByLineAndCol(line, col))
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(segments[len(segments)-1].Key).To(Equal(key))
+		},
+		Entry("at escaped quote in double quoted key", 1, 17, `double "quoted"`),
+		Entry("at closing quote of double quoted key", 1, 19, `double "quoted"`),
+		Entry("at continued line of double quoted value", 2, 6, `double "quoted"`),
+		Entry("at closing quote of single quoted value", 3, 15, "single"),
+		Entry("at anchor", 4, 11, "anchored"),
+		Entry("at tag", 4, 19, "anchored"),
+		Entry("at end of tagged value", 4, 30, "anchored"),
+		Entry("at alias", 5, 8, "alias"),
+		Entry("at empty value", 6, 7, "empty"),
+		Entry("at multibyte value", 7, 5, "あい"),
+	)
+
+	DescribeTable("PathAtPoint() should not resolve outside of the token",
+		func(line, col int) {
+			_, err := yaml.SegmentsAtPoint(dmatcher.NewNodeMatcherByLineAndCol(line, col))
+
+			Expect(err).To(BeAssignableToTypeOf(dyaml.TokenNotFoundError{}))
+		},
+		Entry("after single quoted value", 3, 16),
+		Entry("after alias", 5, 15),
+	)
+
+	Context("with multi-line scalars", func() {
+		multiline := []byte(`script: |
+  echo one
+
+  echo two
+folded: >-
+  first
+  second
+plain: first
+  second
+
+  third
+after: value
+nested:
+  - |
+    line
+  - next
+` + "trailing: foo \t\n  bar  \n    baz\n")
+
+		BeforeEach(func() {
+			var err error
+			yaml, err = dyaml.NewStream(bytes.NewReader(multiline))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		DescribeTable("PathAtPoint() should resolve lines inside the scalar to the owning key",
+			func(line int, expected string) {
+				segments, err := yaml.SegmentsAtPoint(dmatcher.NewNodeMatcherByLine(line))
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(segments.String()).To(Equal(expected))
+			},
+			Entry("in literal scalar", 2, "/script"),
+			Entry("at empty line in literal scalar", 3, "/script"),
+			Entry("at last line of literal scalar", 4, "/script"),
+			Entry("in folded scalar", 7, "/folded"),
+			Entry("in plain scalar", 9, "/plain"),
+			Entry("at last line of plain scalar", 11, "/plain"),
+			Entry("after plain scalar", 12, "/after"),
+			Entry("in literal scalar of sequence item", 15, "/nested/0"),
+			Entry("after literal scalar of sequence item", 16, "/nested/1"),
+			Entry("in plain scalar after trailing blanks", 18, "/trailing"),
+			Entry("at last line of plain scalar after trailing blanks", 19, "/trailing"),
+		)
+
+		It("PathAtPoint() should resolve column inside plain scalar after trailing blanks", func() {
+			segments, err := yaml.SegmentsAtPoint(dmatcher.NewNodeMatcherByLineAndCol(19, 6))
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(segments.String()).To(Equal("/trailing"))
+		})
+
+		It("PathAtPoint() should resolve column inside literal scalar", func() {
+			segments, err := yaml.SegmentsAtPoint(dmatcher.NewNodeMatcherByLineAndCol(4, 8))
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(segments.String()).To(Equal("/script"))
+		})
+	})
+})
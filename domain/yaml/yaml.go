@@ -1,16 +1,25 @@
 package yaml
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"strconv"
 
 	"github.com/gidoichi/yaml-path/domain/matcher"
+	"github.com/gidoichi/yaml-path/domain/source"
 	yamlv3 "gopkg.in/yaml.v3"
 )
 
 type YAML []yamlv3.Node
 
+// Stream is the documents with the source they are decoded from, which gives
+// the ranges of tokens including quotes, anchors and tags.
+type Stream struct {
+	Documents YAML
+	source    *source.Source
+}
+
 type TokenNotFoundError struct {
 	Matcher matcher.NodeMatcher
 }
@@ -19,10 +28,40 @@ func (e TokenNotFoundError) Error() string {
 	return fmt.Sprintf("token not found by %s", e.Matcher)
 }
 
+type PathNotFoundError struct {
+	Segments Segments
+}
+
+func (e PathNotFoundError) Error() string {
+	return fmt.Sprintf("path not found: %s", e.Segments)
+}
+
+// Location is a position of a node in yaml documents.
+// Line and Column are 1-based as yaml.v3 reports them.
+type Location struct {
+	Document int
+	Line     int
+	Column   int
+}
+
 func NewYAML(in io.Reader) (*YAML, error) {
-	var dyaml YAML
+	stream, err := NewStream(in)
+	if err != nil {
+		return nil, err
+	}
+	return &stream.Documents, nil
+}
+
+func NewStream(in io.Reader) (*Stream, error) {
+	data, err := io.ReadAll(in)
+	if err != nil {
+		return nil, err
+	}
+	dyaml := Stream{
+		source: source.NewSource(data),
+	}
 
-	decoder := yamlv3.NewDecoder(in)
+	decoder := yamlv3.NewDecoder(bytes.NewReader(data))
 	for {
 		var node yamlv3.Node
 		if err := decoder.Decode(&node); err != nil {
@@ -31,39 +70,223 @@ func NewYAML(in io.Reader) (*YAML, error) {
 			}
 			break
 		}
-		dyaml = append(dyaml, node)
+		dyaml.Documents = append(dyaml.Documents, node)
 	}
 
 	return &dyaml, nil
 }
 
+// Source returns the source the documents are decoded from.
+func (y *Stream) Source() *source.Source {
+	return y.source
+}
+
+// PathAtPoint returns the path to the token the matcher matches. Without the
+// source, tokens are matched by the positions of their values.
 func (y *YAML) PathAtPoint(matcher matcher.NodeMatcher) (Path, error) {
-	for _, document := range *y {
-		rev, found := y.findMatchedToken(matcher, &document)
+	return (&Stream{Documents: *y}).PathAtPoint(matcher)
+}
+
+func (y *Stream) PathAtPoint(matcher matcher.NodeMatcher) (Path, error) {
+	resolved, err := y.resolve(matcher)
+	if err != nil {
+		return nil, err
+	}
+	for i := range y.Documents {
+		rev, found := y.findMatchedToken(resolved, &y.Documents[i])
 		if !found {
 			continue
 		}
 
-		y.reverse(rev)
-		path := rev
-		len := path.Len()
-		if path[len-3].Kind == yamlv3.MappingNode || path[len-3].Kind == yamlv3.SequenceNode {
-			path = path[:len-1]
+		return y.trimValue(y.reverse(rev)), nil
+	}
+	return nil, TokenNotFoundError{
+		Matcher: matcher,
+	}
+}
+
+// PathEnclosingPoint returns the path PathAtPoint returns, or the path to the
+// nearest node enclosing the point if no token matches. For example, a dash of
+// sequence resolves to the sequence item, a colon of mapping resolves to the
+// mapping entry, and a trailing comment resolves to the node the line ends
+// with. With matcher.NodeMatcherByRange, it returns the path to the smallest
+// node containing the range.
+func (y *Stream) PathEnclosingPoint(matcher matcher.NodeMatcher) (Path, error) {
+	path, err := y.PathAtPoint(matcher)
+	if _, ok := err.(TokenNotFoundError); !ok {
+		return path, err
+	}
+
+	resolved, err := y.resolve(matcher)
+	if err != nil {
+		return nil, err
+	}
+	for i := range y.Documents {
+		rev, found := y.findEnclosingNode(resolved, &y.Documents[i])
+		if !found {
+			continue
 		}
-		return path, nil
+
+		return y.trimValue(y.reverse(rev)), nil
 	}
 	return nil, TokenNotFoundError{
 		Matcher: matcher,
 	}
 }
 
+// SegmentsAtPoint returns segments of the path PathAtPoint returns.
+func (y *Stream) SegmentsAtPoint(matcher matcher.NodeMatcher) (Segments, error) {
+	path, err := y.PathAtPoint(matcher)
+	if err != nil {
+		return nil, err
+	}
+	return y.Segments(path)
+}
+
+// Segments converts the path taken from the documents into segments, with the
+// index of the document and the ranges in the source.
+func (y *Stream) Segments(path Path) (Segments, error) {
+	segments, err := path.Segments()
+	if err != nil {
+		return nil, err
+	}
+	for i := range y.Documents {
+		if len(segments) > 0 && segments[0].Node == &y.Documents[i] {
+			segments[0].Index = i
+		}
+	}
+	for i := range segments {
+		segment := &segments[i]
+		if segment.Node == nil {
+			continue
+		}
+		segment.ValueSpan = y.extent(segment.Node)
+		switch segment.Kind {
+		case KeySegment:
+			segment.Span = source.Span{
+				Start: source.Position{Line: segment.Line, Column: segment.Column},
+				End:   segment.ValueSpan.End,
+			}
+		case IndexSegment:
+			segment.Span = y.itemSpan(segment.Parent, segment.Node)
+		default:
+			segment.Span = segment.ValueSpan
+		}
+	}
+	return segments, nil
+}
+
+// Locate returns the location of the node which the segments indicate.
+//
+// When the last segment indicates a mapping entry, the location of the key
+// token is returned. If the segments start with a document segment having an
+// index, only the document is searched.
+func (y *Stream) Locate(segments Segments) (location Location, err error) {
+	for i := range y.Documents {
+		document := &y.Documents[i]
+		rest := segments
+		if len(rest) > 0 && rest[0].Kind == DocumentSegment {
+			if 0 <= rest[0].Index && rest[0].Index != i {
+				continue
+			}
+			rest = rest[1:]
+		}
+		node, found := y.findNodeBySegments(rest, document)
+		if !found {
+			continue
+		}
+
+		return Location{
+			Document: i,
+			Line:     node.Line,
+			Column:   node.Column,
+		}, nil
+	}
+	return Location{}, PathNotFoundError{
+		Segments: segments,
+	}
+}
+
+func (y *Stream) findNodeBySegments(segments Segments, node *yamlv3.Node) (token *yamlv3.Node, match bool) {
+	if node.Kind == yamlv3.DocumentNode {
+		if len(node.Content) == 0 {
+			return nil, false
+		}
+		return y.findNodeBySegments(segments, node.Content[0])
+	}
+	if len(segments) == 0 {
+		return node, true
+	}
+
+	segment, rest := segments[0], segments[1:]
+	switch node.Kind {
+	case yamlv3.SequenceNode:
+		idx := -1
+		switch segment.Kind {
+		case IndexSegment:
+			if segment.Index < len(node.Content) {
+				idx = segment.Index
+			}
+		case SelectorSegment:
+			idx = (*Node)(node).FindSequenceIndexByMappingKey(segment.Key, segment.Value)
+		}
+		if idx < 0 {
+			return nil, false
+		}
+		return y.findNodeBySegments(rest, node.Content[idx])
+
+	case yamlv3.MappingNode:
+		var key string
+		switch segment.Kind {
+		case KeySegment:
+			key = segment.Key
+		case IndexSegment:
+			key = strconv.Itoa(segment.Index)
+		default:
+			return nil, false
+		}
+		for i := 0; i < len(node.Content); i += 2 {
+			keyNode := node.Content[i]
+			if keyNode.Value != key {
+				continue
+			}
+			if len(rest) == 0 {
+				return keyNode, true
+			}
+			return y.findNodeBySegments(rest, node.Content[i+1])
+		}
+		// keys not written in the mapping are looked up in merged mappings
+		for i := 0; i < len(node.Content); i += 2 {
+			if node.Content[i].ShortTag() != mergeTag {
+				continue
+			}
+			merged := []*yamlv3.Node{node.Content[i+1]}
+			if node.Content[i+1].Kind == yamlv3.SequenceNode {
+				merged = node.Content[i+1].Content
+			}
+			for _, m := range merged {
+				if token, found := y.findNodeBySegments(segments, m); found {
+					return token, true
+				}
+			}
+		}
+
+	case yamlv3.AliasNode:
+		if node.Alias != nil {
+			return y.findNodeBySegments(segments, node.Alias)
+		}
+	}
+
+	return nil, false
+}
+
 // findTokenAtPoint returns token path the arguments indicated.
 // Note that returned path is reversed order.
 //
 // For example, when yaml path is $.top.first[0].attr2, then
 // returned value is reversed order of (Document -> Mapping -> Scaler{"top"} ->
 // Mapping -> Scaler{"first"} -> Sequence -> Scaler{"0"} -> Mapping -> Scaler{"attr2"}).
-func (y *YAML) findMatchedToken(matcher matcher.NodeMatcher, node *yamlv3.Node) (revpath Path, match bool) {
+func (y *Stream) findMatchedToken(matcher matcher.NodeMatcher, node *yamlv3.Node) (revpath Path, match bool) {
 	switch node.Kind {
 	case yamlv3.DocumentNode:
 		for _, child := range node.Content {
@@ -115,11 +338,86 @@ func (y *YAML) findMatchedToken(matcher matcher.NodeMatcher, node *yamlv3.Node)
 	return nil, false
 }
 
-func (y *YAML) node_match(matcher matcher.NodeMatcher, node *yamlv3.Node) bool {
-	return matcher.Match(node)
+// resolve converts the position of the matcher into the coordinates of
+// yaml.v3, if it depends on the source.
+func (y *Stream) resolve(m matcher.NodeMatcher) (matcher.NodeMatcher, error) {
+	if sm, ok := m.(matcher.SourceNodeMatcher); ok {
+		return sm.Resolve(y.source)
+	}
+	return m, nil
+}
+
+// findEnclosingNode returns reversed token path to the deepest node, whose
+// range written in the source matches. Note that the range of mapping entry
+// and sequence item in block style extends to the end of the line.
+func (y *Stream) findEnclosingNode(matcher matcher.NodeMatcher, node *yamlv3.Node) (revpath Path, match bool) {
+	switch node.Kind {
+	case yamlv3.DocumentNode:
+		for _, child := range node.Content {
+			if !y.match(matcher, child, y.toLineEnd(child, y.extent(child))) {
+				continue
+			}
+			p, _ := y.findEnclosingNode(matcher, child)
+			return append(p, node), true
+		}
+
+	case yamlv3.SequenceNode:
+		for i, child := range node.Content {
+			if !y.match(matcher, child, y.itemRegion(node, child)) {
+				continue
+			}
+			index := &yamlv3.Node{
+				Kind:  yamlv3.ScalarNode,
+				Tag:   intTag,
+				Value: strconv.Itoa(i),
+			}
+			p, _ := y.findEnclosingNode(matcher, child)
+			return append(p, index, node), true
+		}
+
+	case yamlv3.MappingNode:
+		for i := 0; i < len(node.Content); i += 2 {
+			keyNode := node.Content[i]
+			valNode := node.Content[i+1]
+			if !y.match(matcher, keyNode, y.pairRegion(node, keyNode, valNode)) {
+				continue
+			}
+			p, _ := y.findEnclosingNode(matcher, valNode)
+			return append(p, keyNode, node), true
+		}
+
+	case yamlv3.ScalarNode, yamlv3.AliasNode:
+		if y.node_match(matcher, node) {
+			return Path{node}, true
+		}
+	}
+
+	return nil, false
+}
+
+// trimValue removes the value node at the end of the path, because the path
+// to a mapping value or a sequence item ends with the key or the index.
+func (y *Stream) trimValue(path Path) Path {
+	len := path.Len()
+	if len < 3 {
+		return path
+	}
+	if path[len-3].Kind == yamlv3.MappingNode || path[len-3].Kind == yamlv3.SequenceNode {
+		return path[:len-1]
+	}
+	return path
+}
+
+func (y *Stream) node_match(matcher matcher.NodeMatcher, node *yamlv3.Node) bool {
+	return y.match(matcher, node, y.tokenSpan(node))
+}
+
+// match matches the node by the span in the source, if the matcher supports.
+func (y *Stream) match(m matcher.NodeMatcher, node *yamlv3.Node, span source.Span) bool {
+	return matcher.MatchSpan(m, node, span)
 }
 
-func (y *YAML) reverse(p Path) (path Path) {
+func (y *Stream) reverse(p Path) (path Path) {
 	len := len(p)
 	for i := len/2 - 1; i >= 0; i-- {
 		opp := len - i - 1
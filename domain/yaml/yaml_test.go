@@ -11,6 +11,18 @@ import (
 	yamlv3 "gopkg.in/yaml.v3"
 )
 
+// valueMatcher implements only NodeMatcher, as matchers written before the
+// source span was available.
+type valueMatcher string
+
+func (m valueMatcher) Match(node *yamlv3.Node) bool {
+	return node.Value == string(m)
+}
+
+func (m valueMatcher) String() string {
+	return string(m)
+}
+
 var _ = Describe("YAML", func() {
 	data := []byte(`top:
   first:
@@ -72,16 +84,46 @@ second:
 				Expect(err).To(HaveOccurred())
 			})
 		})
+
+		Context("indicating by matcher", func() {
+			It("should return the path to the token", func() {
+				yaml, err := dyaml.NewYAML(bytes.NewReader(data))
+				Expect(err).NotTo(HaveOccurred())
+
+				path, err := yaml.PathAtPoint(dmatcher.NewNodeMatcherByLineAndCol(5, 14))
+				Expect(err).NotTo(HaveOccurred())
+
+				segments, err := path.Segments()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(segments.String()).To(Equal("/top/first/0/attr2"))
+			})
+		})
+	})
+
+	Describe("NewStream()", func() {
+		It("should return the documents with the source", func() {
+			stream, err := dyaml.NewStream(bytes.NewReader(multi))
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stream.Documents).To(HaveLen(2))
+			Expect(stream.Source()).NotTo(BeNil())
+		})
+
+		It("should return an error with invalid yaml", func() {
+			_, err := dyaml.NewStream(bytes.NewReader([]byte("top: -")))
+
+			Expect(err).To(HaveOccurred())
+		})
 	})
 
 	Describe("PathAtPoint()", func() {
-		var yaml *dyaml.YAML
+		var yaml *dyaml.Stream
 
 		Context("with single document yaml", func() {
 			BeforeEach(func() {
 				var err error
 				reader = bytes.NewReader(data)
-				yaml, err = dyaml.NewYAML(reader)
+				yaml, err = dyaml.NewStream(reader)
 				Expect(err).NotTo(HaveOccurred())
 			})
 
@@ -109,6 +151,15 @@ second:
 				})
 			})
 
+			Context("indicating by matcher without span", func() {
+				It("should return the path to the token", func() {
+					segments, err := yaml.SegmentsAtPoint(valueMatcher("val2"))
+
+					Expect(err).NotTo(HaveOccurred())
+					Expect(segments.String()).To(Equal("/top/first/0/attr2"))
+				})
+			})
+
 			Context("indicating at sequence value node", func() {
 				matcher := dmatcher.NewNodeMatcherByLineAndCol(7, 7)
 
@@ -141,11 +192,122 @@ second:
 			})
 		})
 
+		Context("with column counted in other units", func() {
+			wide := []byte("キー: 値\t# 😀\nk:\t\"😀x\"\n")
+
+			BeforeEach(func() {
+				var err error
+				reader = bytes.NewReader(wide)
+				yaml, err = dyaml.NewStream(reader)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			DescribeTable("should return the path to the token",
+				func(line, col int, column dmatcher.Column, expected string) {
+					path, err := yaml.PathAtPoint(dmatcher.NewNodeMatcherByLineAndColIn(line, col, column))
+					Expect(err).NotTo(HaveOccurred())
+
+					segments, err := path.Segments()
+					Expect(err).NotTo(HaveOccurred())
+					Expect(segments.String()).To(Equal(expected))
+				},
+				Entry("in 0-based bytes", 1, 9, dmatcher.Column{Unit: dmatcher.ColumnByte, Base: 0}, "/キー"),
+				Entry("in 1-based bytes", 2, 9, dmatcher.Column{Unit: dmatcher.ColumnByte, Base: 1}, "/k"),
+				Entry("in UTF-16 code units", 2, 6, dmatcher.Column{Unit: dmatcher.ColumnUTF16, Base: 0}, "/k"),
+				Entry("in visual columns", 1, 6, dmatcher.Column{Unit: dmatcher.ColumnVisual, Base: 0, TabWidth: 8}, "/キー"),
+				Entry("in visual columns after tab", 2, 8, dmatcher.Column{Unit: dmatcher.ColumnVisual, Base: 0, TabWidth: 8}, "/k"),
+			)
+
+			It("should not match column after the token", func() {
+				_, err := yaml.PathAtPoint(dmatcher.NewNodeMatcherByLineAndColIn(1, 8, dmatcher.Column{Unit: dmatcher.ColumnVisual, Base: 0, TabWidth: 8}))
+
+				Expect(err).To(BeAssignableToTypeOf(dyaml.TokenNotFoundError{}))
+			})
+
+			It("should fail with unsupported unit", func() {
+				_, err := yaml.PathAtPoint(dmatcher.NewNodeMatcherByLineAndColIn(1, 1, dmatcher.Column{Unit: "dummy", Base: 0}))
+
+				Expect(err).To(HaveOccurred())
+				Expect(err).NotTo(BeAssignableToTypeOf(dyaml.TokenNotFoundError{}))
+			})
+		})
+
+		Context("with offset in multiple document yaml having CRLF", func() {
+			crlf := []byte("a: x\r\nb: y\r\n---\nc: あz\n")
+
+			BeforeEach(func() {
+				var err error
+				reader = bytes.NewReader(crlf)
+				yaml, err = dyaml.NewStream(reader)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			DescribeTable("should return the path to the token",
+				func(offset int, unit dmatcher.ColumnUnit, base int, expected string) {
+					segments, err := yaml.SegmentsAtPoint(dmatcher.NewNodeMatcherByOffset(offset, unit, base))
+
+					Expect(err).NotTo(HaveOccurred())
+					Expect(segments.String()).To(Equal(expected))
+				},
+				Entry("at first token", 0, dmatcher.ColumnByte, 0, "/a"),
+				Entry("after CRLF", 6, dmatcher.ColumnByte, 0, "/b"),
+				Entry("in second document", 22, dmatcher.ColumnByte, 0, "/c"),
+				Entry("in 1-based runes", 21, dmatcher.ColumnRune, 1, "/c"),
+			)
+
+			It("should not match at line break", func() {
+				_, err := yaml.PathAtPoint(dmatcher.NewNodeMatcherByOffset(5, dmatcher.ColumnByte, 0))
+
+				Expect(err).To(BeAssignableToTypeOf(dyaml.TokenNotFoundError{}))
+			})
+
+			It("should fail with offset out of range", func() {
+				_, err := yaml.PathAtPoint(dmatcher.NewNodeMatcherByOffset(100, dmatcher.ColumnByte, 0))
+
+				Expect(err).To(HaveOccurred())
+				Expect(err).NotTo(BeAssignableToTypeOf(dyaml.TokenNotFoundError{}))
+			})
+		})
+
+		Context("with yaml starting with byte order mark", func() {
+			bom := []byte("\xEF\xBB\xBFabc: \"x y\"\n")
+
+			BeforeEach(func() {
+				var err error
+				reader = bytes.NewReader(bom)
+				yaml, err = dyaml.NewStream(reader)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			DescribeTable("should return the path to the token at column",
+				func(col int) {
+					segments, err := yaml.SegmentsAtPoint(dmatcher.NewNodeMatcherByLineAndCol(1, col))
+
+					Expect(err).NotTo(HaveOccurred())
+					Expect(segments.String()).To(Equal("/abc"))
+				},
+				Entry("at key", 2),
+				Entry("at quoted value", 6),
+			)
+
+			DescribeTable("should return the path to the token at offset",
+				func(offset int, unit dmatcher.ColumnUnit) {
+					segments, err := yaml.SegmentsAtPoint(dmatcher.NewNodeMatcherByOffset(offset, unit, 0))
+
+					Expect(err).NotTo(HaveOccurred())
+					Expect(segments.String()).To(Equal("/abc"))
+				},
+				Entry("in bytes", 3, dmatcher.ColumnByte),
+				Entry("in runes", 1, dmatcher.ColumnRune),
+				Entry("inside byte order mark", 1, dmatcher.ColumnByte),
+			)
+		})
+
 		Context("with multiple document yaml", func() {
 			BeforeEach(func() {
 				var err error
 				reader = bytes.NewReader(multi)
-				yaml, err = dyaml.NewYAML(reader)
+				yaml, err = dyaml.NewStream(reader)
 				Expect(err).NotTo(HaveOccurred())
 			})
 
@@ -168,4 +330,195 @@ second:
 			})
 		})
 	})
+
+	Describe("PathEnclosingPoint()", func() {
+		var yaml *dyaml.Stream
+
+		BeforeEach(func() {
+			var err error
+			reader = bytes.NewReader(data)
+			yaml, err = dyaml.NewStream(reader)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		DescribeTable("should return the path to the enclosing node",
+			func(line, col int, expected string) {
+				path, err := yaml.PathEnclosingPoint(dmatcher.NewNodeMatcherByLineAndCol(line, col))
+				Expect(err).NotTo(HaveOccurred())
+
+				segments, err := path.Segments()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(segments.String()).To(Equal(expected))
+			},
+			Entry("at token", 5, 14, "/top/first/0/attr2"),
+			Entry("at sequence dash", 7, 5, "/top/first/1"),
+			Entry("at sequence dash followed by mapping", 3, 5, "/top/first/0"),
+			Entry("at mapping colon", 5, 12, "/top/first/0/attr2"),
+			Entry("at trailing whitespace", 5, 30, "/top/first/0/attr2"),
+			Entry("at comment line", 6, 14, "/top/first"),
+			Entry("at indentation", 2, 1, "/top"),
+		)
+
+		DescribeTable("should return the path to the smallest node containing the range",
+			func(start, end dmatcher.NodeMatcher, expected string) {
+				path, err := yaml.PathEnclosingPoint(dmatcher.NewNodeMatcherByRange(start, end))
+				Expect(err).NotTo(HaveOccurred())
+
+				segments, err := path.Segments()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(segments.String()).To(Equal(expected))
+			},
+			Entry("in a token",
+				dmatcher.NewNodeMatcherByLineAndCol(5, 14), dmatcher.NewNodeMatcherByLineAndCol(5, 16), "/top/first/0/attr2"),
+			Entry("over key and value",
+				dmatcher.NewNodeMatcherByLineAndCol(5, 7), dmatcher.NewNodeMatcherByLineAndCol(5, 16), "/top/first/0/attr2"),
+			Entry("over sibling mapping entries",
+				dmatcher.NewNodeMatcherByLineAndCol(4, 7), dmatcher.NewNodeMatcherByLineAndCol(5, 9), "/top/first/0"),
+			Entry("over sibling sequence items",
+				dmatcher.NewNodeMatcherByLineAndCol(7, 7), dmatcher.NewNodeMatcherByLineAndCol(8, 8), "/top/first"),
+			Entry("over lines of a sequence item",
+				dmatcher.NewNodeMatcherByLine(3), dmatcher.NewNodeMatcherByLine(5), "/top/first/0"),
+			Entry("over lines of the document",
+				dmatcher.NewNodeMatcherByLine(1), dmatcher.NewNodeMatcherByLine(12), "/top"),
+		)
+	})
+
+	Describe("Locate()", func() {
+		var yaml *dyaml.Stream
+
+		Context("with single document yaml", func() {
+			BeforeEach(func() {
+				var err error
+				reader = bytes.NewReader(data)
+				yaml, err = dyaml.NewStream(reader)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			Context("indicating mapping key through sequence selector", func() {
+				segments := dyaml.Segments{
+					dyaml.NewDocumentSegment(),
+					dyaml.NewKeySegment("top"),
+					dyaml.NewKeySegment("first"),
+					dyaml.NewSelectorSegment("name", "myname"),
+					dyaml.NewKeySegment("attr2"),
+				}
+
+				It("should return the location of the key", func() {
+					location, err := yaml.Locate(segments)
+
+					Expect(err).NotTo(HaveOccurred())
+					Expect(location).To(Equal(dyaml.Location{Document: 0, Line: 5, Column: 7}))
+				})
+			})
+
+			Context("indicating sequence item by index", func() {
+				segments := dyaml.Segments{
+					dyaml.NewKeySegment("top"),
+					dyaml.NewKeySegment("first"),
+					dyaml.NewIndexSegment(1),
+				}
+
+				It("should return the location of the item", func() {
+					location, err := yaml.Locate(segments)
+
+					Expect(err).NotTo(HaveOccurred())
+					Expect(location).To(Equal(dyaml.Location{Document: 0, Line: 7, Column: 7}))
+				})
+			})
+
+			Context("indicating sequence selector no item matches", func() {
+				segments := dyaml.Segments{
+					dyaml.NewKeySegment("top"),
+					dyaml.NewKeySegment("first"),
+					dyaml.NewSelectorSegment("name", "dummy"),
+				}
+
+				It("should return path not found error", func() {
+					_, err := yaml.Locate(segments)
+
+					Expect(err).To(BeAssignableToTypeOf(dyaml.PathNotFoundError{}))
+				})
+			})
+
+			Context("indicating no node", func() {
+				segments := dyaml.Segments{
+					dyaml.NewKeySegment("top"),
+					dyaml.NewKeySegment("third"),
+				}
+
+				It("should return path not found error", func() {
+					_, err := yaml.Locate(segments)
+
+					Expect(err).To(BeAssignableToTypeOf(dyaml.PathNotFoundError{}))
+				})
+			})
+		})
+
+		Context("with merge keys", func() {
+			BeforeEach(func() {
+				var err error
+				reader = bytes.NewReader([]byte(`a: &a {x: 1}
+b: &b {x: 2, y: 2}
+obj:
+  <<: [*a, *b]
+  z: 3
+`))
+				yaml, err = dyaml.NewStream(reader)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			DescribeTable("should return the location of the key in the merged mapping",
+				func(key string, expected dyaml.Location) {
+					location, err := yaml.Locate(dyaml.Segments{
+						dyaml.NewKeySegment("obj"),
+						dyaml.NewKeySegment(key),
+					})
+
+					Expect(err).NotTo(HaveOccurred())
+					Expect(location).To(Equal(expected))
+				},
+				Entry("in the first mapping", "x", dyaml.Location{Document: 0, Line: 1, Column: 8}),
+				Entry("in the second mapping", "y", dyaml.Location{Document: 0, Line: 2, Column: 14}),
+				Entry("written in the mapping", "z", dyaml.Location{Document: 0, Line: 5, Column: 3}),
+			)
+		})
+
+		Context("with multiple document yaml", func() {
+			BeforeEach(func() {
+				var err error
+				reader = bytes.NewReader(multi)
+				yaml, err = dyaml.NewStream(reader)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			Context("indicating node in the second document", func() {
+				segments := dyaml.Segments{
+					dyaml.NewKeySegment("second"),
+					dyaml.NewIndexSegment(0),
+				}
+
+				It("should return the location with document index", func() {
+					location, err := yaml.Locate(segments)
+
+					Expect(err).NotTo(HaveOccurred())
+					Expect(location).To(Equal(dyaml.Location{Document: 1, Line: 5, Column: 5}))
+				})
+			})
+
+			Context("indicating node in other document than specified", func() {
+				document := dyaml.NewDocumentSegment()
+				document.Index = 0
+				segments := dyaml.Segments{
+					document,
+					dyaml.NewKeySegment("second"),
+				}
+
+				It("should return path not found error", func() {
+					_, err := yaml.Locate(segments)
+
+					Expect(err).To(BeAssignableToTypeOf(dyaml.PathNotFoundError{}))
+				})
+			})
+		})
+	})
 })
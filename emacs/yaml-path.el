@@ -40,7 +40,9 @@
                     (with-current-buffer outbuf (erase-buffer))
                     (call-process-region
                      (point-min) (point-max) yaml-path-bin nil outbuf nil
-                     "--line" line "--col" col "--format" yaml-path-output-format)))
+                     "--line" line "--col" col "--col-unit" "visual" "--col-base" "0"
+                     "--tab-width" (number-to-string tab-width)
+                     "--format" yaml-path-output-format)))
            (with-current-buffer outbuf
              (setq result (replace-regexp-in-string "\n+" "" (buffer-string)))))
           ((zerop (progn
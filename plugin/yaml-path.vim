@@ -8,7 +8,7 @@ endif
 
 function! Yamlpath(...)
   let sep = a:0 ? a:1 : g:yamlpath_sep
-  let clean = systemlist('yaml-path --sep=' . sep . ' --line ' . line('.') . ' --col ' . string(col('.')-1), join(getline(1,'$') , "\n"))[0]
+  let clean = systemlist('yaml-path --sep=' . sep . ' --line ' . line('.') . ' --col-unit byte --col-base 0 --col ' . string(col('.')-1), join(getline(1,'$') , "\n"))[0]
   return clean
 endfunction
 
@@ -1,12 +1,16 @@
 package cli
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"runtime/debug"
+	"strings"
 
 	dmatcher "github.com/gidoichi/yaml-path/domain/matcher"
+	"github.com/gidoichi/yaml-path/domain/source"
 	ppath "github.com/gidoichi/yaml-path/presentation/path"
 	"github.com/urfave/cli/v3"
 )
@@ -17,17 +21,26 @@ func Run() {
 	cmd := &cli.Command{
 		ArgsUsage: "--line uint",
 		Usage:     "Reads yaml and output a path corresponding to leftmost token at line, or at (line, col)",
-		Flags: []cli.Flag{
+		Flags: append(cursorFlags(),
 			&cli.UintFlag{
-				Name:     "line",
-				Usage:    "cursor line",
-				Required: true,
-				Hidden:   true,
+				Name:  "end-line",
+				Usage: "selection end line, to output the path to the smallest node containing the selection",
+				Local: true,
 			},
 			&cli.UintFlag{
-				Name:  "col",
-				Usage: "cursor column, zero to disable",
-				Value: 0,
+				Name:  "end-col",
+				Usage: "selection end column, counted same as col, used with end-line",
+				Local: true,
+			},
+			&cli.UintFlag{
+				Name:  "end-offset",
+				Usage: "selection end offset, counted same as offset",
+				Local: true,
+			},
+			&cli.BoolFlag{
+				Name:  "enclosing",
+				Usage: "resolve to the nearest enclosing node when no token is at the cursor",
+				Local: true,
 			},
 			&cli.StringFlag{
 				Name:  "path",
@@ -35,9 +48,13 @@ func Run() {
 			},
 			&cli.StringFlag{
 				Name:  "format",
-				Usage: `output format. "bosh" or "jsonpath"`,
+				Usage: "output format. " + strings.Join(ppath.Formatters(), ", ") + ", or <format> of " + ppath.PluginPrefix + "<format> executable in PATH",
 				Value: "bosh",
 			},
+			&cli.StringSliceFlag{
+				Name:  "format-option",
+				Usage: "set option of the formatter as key=value",
+			},
 			&cli.StringFlag{
 				Name:  "bosh.sep",
 				Usage: "set path separator for bosh format",
@@ -48,54 +65,124 @@ func Run() {
 				Usage: "set attribut name for bosh format, empty to disable",
 				Value: "name",
 			},
+			&cli.StringFlag{
+				Name:  "jsonpath.name",
+				Usage: "set attribute name to select sequence items by filter selector for jsonpath format, empty to disable",
+			},
+			&cli.StringFlag{
+				Name:  "yq.name",
+				Usage: "set attribute name to select sequence items by for yq format, empty to disable",
+			},
+			&cli.StringFlag{
+				Name:  "kustomize.name",
+				Usage: "set attribute name to select sequence items by for kustomize format, empty to disable",
+				Value: "name",
+			},
+			&cli.StringFlag{
+				Name:  "properties.sep",
+				Usage: "set key separator for properties format",
+				Value: ".",
+			},
+			&cli.StringFlag{
+				Name:  "properties.index",
+				Usage: `set index style for properties format. "bracket" or "separator"`,
+				Value: string(ppath.IndexStyleBracket),
+			},
+			&cli.StringFlag{
+				Name:  "env.prefix",
+				Usage: "set variable name prefix for env format",
+			},
+			&cli.StringFlag{
+				Name:  "env.sep",
+				Usage: "set separator for env format",
+				Value: "_",
+			},
+			&cli.StringFlag{
+				Name:  "env.case",
+				Usage: `set case of keys for env format. "upper", "lower" or "preserve"`,
+				Value: string(ppath.CaseUpper),
+			},
+			&cli.StringFlag{
+				Name:  "env.index",
+				Usage: `set index style for env format. "separator" or "none"`,
+				Value: string(ppath.IndexStyleSeparator),
+			},
+			&cli.StringFlag{
+				Name:  "terraform.root",
+				Usage: "set expression of the decoded document for terraform format",
+				Value: "local.cfg",
+			},
+			&cli.StringFlag{
+				Name:  "jq.name",
+				Usage: "set attribute name to select sequence items by for jq format, empty to disable",
+			},
+			&cli.StringFlag{
+				Name:  "subscript.root",
+				Usage: "set expression of the loaded document for python and javascript format",
+				Value: "data",
+			},
+			&cli.StringFlag{
+				Name:  "gotemplate.root",
+				Usage: `set field chain of the document for gotemplate format, e.g. ".Values"`,
+			},
+			&cli.StringFlag{
+				Name:  "template.text",
+				Usage: `set Go template defining "document", "key", "index" and "selector" for template format`,
+			},
+			&cli.StringFlag{
+				Name:  "template.file",
+				Usage: "set file to read the template from for template format",
+			},
+			&cli.StringFlag{
+				Name:  "template.name",
+				Usage: `set attribute name to select sequence items by with "selector" for template format, empty to disable`,
+				Value: "name",
+			},
+			&cli.StringFlag{
+				Name:  "kubectl.kind",
+				Usage: "set resource kind for kubectl format, empty to take from the document",
+			},
+		),
+		Commands: []*cli.Command{
+			locateCommand(),
+			extentCommand(),
+			opsCommand(),
+			patchCommand(),
 		},
 		HideHelpCommand: true,
 		Action: func(ctx context.Context, c *cli.Command) error {
-			var file *os.File
-			var err error
-			line := c.Uint("line")
-			col := c.Uint("col")
-			filePath := c.String("path")
-			format := c.String("format")
-
-			var formatter ppath.PathFormatter
-			switch format {
-			case "bosh":
-				f := &ppath.PathFormatterBosh{}
-				if sep := c.String("bosh.sep"); sep != "" {
-					f.Separator = sep
-				}
-				if attr := c.String("bosh.name"); attr != "" {
-					f.NameAttr = attr
-				}
-				formatter = f
-			case "jsonpath":
-				formatter = &ppath.PathFormatterJSONPath{}
-			default:
-				return cli.Exit(fmt.Errorf("unsupported path format: %s", format), 1)
+			if c.IsSet("end-col") && !c.IsSet("end-line") {
+				return cli.Exit(`Flag "end-col" requires "end-line"`, 1)
+			}
+			file, matcher, err := cursorInput(c)
+			if err != nil {
+				return err
 			}
 
-			if filePath != "" {
-				if file, err = os.Open(filePath); err != nil {
-					return cli.Exit(fmt.Errorf("read from file: %w", err), 1)
-				}
-			} else {
-				file = os.Stdin
+			formatter, err := newFormatter(c)
+			if err != nil {
+				return cli.Exit(err, 1)
 			}
 
-			var matcher dmatcher.NodeMatcher
-			if col == 0 {
-				matcher = dmatcher.NewNodeMatcherByLine(int(line))
-			} else {
-				matcher = dmatcher.NewNodeMatcherByLineAndCol(int(line), int(col))
+			newPath := ppath.NewPath
+			if c.Bool("enclosing") {
+				newPath = ppath.NewEnclosingPath
 			}
-			path, err := ppath.NewPath(file, matcher)
+			if c.IsSet("end-line") || c.IsSet("end-offset") {
+				end, err := newMatcher(c, "end-line", "end-col", "end-offset")
+				if err != nil {
+					return cli.Exit(err, 1)
+				}
+				matcher = dmatcher.NewNodeMatcherByRange(matcher, end)
+				newPath = ppath.NewEnclosingPath
+			}
+			path, err := newPath(file, matcher)
 			if err != nil {
 				return cli.Exit(fmt.Errorf("resolve path: %w", err), 1)
 			}
 			strpath, err := path.ToString(formatter)
 			if err != nil {
-				return cli.Exit(fmt.Errorf("path formatting error: %s", format), 1)
+				return cli.Exit(fmt.Errorf("path formatting error: %w", err), 1)
 			}
 			fmt.Println(strpath)
 
@@ -112,3 +199,358 @@ func Run() {
 
 	cmd.Run(context.Background(), os.Args)
 }
+
+// columnFlags returns the flags to give the way of counting columns.
+func columnFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:  "col-unit",
+			Usage: `unit of column. "rune", "byte", "utf16" or "visual"`,
+			Value: string(dmatcher.ColumnRune),
+			Local: true,
+		},
+		&cli.UintFlag{
+			Name:  "col-base",
+			Usage: "column of the beginning of line, 0 or 1",
+			Value: 1,
+			Local: true,
+		},
+		&cli.UintFlag{
+			Name:  "tab-width",
+			Usage: `tab width for "visual" col-unit`,
+			Value: 8,
+			Local: true,
+		},
+	}
+}
+
+// cursorFlags returns the flags to give the cursor position.
+func cursorFlags() []cli.Flag {
+	flags := []cli.Flag{
+		&cli.UintFlag{
+			Name:   "line",
+			Usage:  "cursor line",
+			Hidden: true,
+			Local:  true,
+		},
+		&cli.UintFlag{
+			Name:  "col",
+			Usage: "cursor column, zero to disable when col-base is 1",
+			Value: 0,
+			Local: true,
+		},
+	}
+	flags = append(flags, columnFlags()...)
+	return append(flags,
+		&cli.UintFlag{
+			Name:  "offset",
+			Usage: "cursor offset from the beginning of input, used instead of line and col",
+			Local: true,
+		},
+		&cli.StringFlag{
+			Name:  "offset-unit",
+			Usage: `unit of cursor offset. "byte", "rune" or "utf16"`,
+			Value: string(dmatcher.ColumnByte),
+			Local: true,
+		},
+		&cli.UintFlag{
+			Name:  "offset-base",
+			Usage: "cursor offset of the beginning of input, 0 or 1",
+			Value: 0,
+			Local: true,
+		},
+	)
+}
+
+// newColumn returns the column given by the flags.
+func newColumn(c *cli.Command) (dmatcher.Column, error) {
+	column := dmatcher.Column{
+		Unit:     dmatcher.ColumnUnit(c.String("col-unit")),
+		Base:     int(c.Uint("col-base")),
+		TabWidth: int(c.Uint("tab-width")),
+	}
+	return column, column.Validate()
+}
+
+// newFormatter returns the path formatter given by the flags.
+func newFormatter(c *cli.Command) (ppath.PathFormatter, error) {
+	options, err := formatterOptions(c)
+	if err != nil {
+		return nil, err
+	}
+	return ppath.NewFormatter(c.String("format"), options)
+}
+
+// formatterOptions returns the options of the formatters. String flags named
+// "<format>.<option>" and "format-option" flags are given as the options.
+func formatterOptions(c *cli.Command) (ppath.FormatterOptions, error) {
+	options := ppath.FormatterOptions{}
+	for _, flag := range c.Root().Flags {
+		if _, ok := flag.(*cli.StringFlag); !ok {
+			continue
+		}
+		for _, name := range flag.Names() {
+			if strings.Contains(name, ".") {
+				options[name] = c.String(name)
+			}
+		}
+	}
+	for _, option := range c.StringSlice("format-option") {
+		key, value, found := strings.Cut(option, "=")
+		if !found {
+			return nil, fmt.Errorf("format option must be key=value: %s", option)
+		}
+		options[key] = value
+	}
+	return options, nil
+}
+
+// cursorInput returns the input and the matcher of the cursor the flags give.
+// The error is to be returned from the action as is.
+func cursorInput(c *cli.Command) (file *os.File, matcher dmatcher.NodeMatcher, err error) {
+	if !c.IsSet("line") && !c.IsSet("offset") {
+		return nil, nil, cli.Exit(`Required flag "line" or "offset" not set`, 1)
+	}
+	if matcher, err = newMatcher(c, "line", "col", "offset"); err != nil {
+		return nil, nil, cli.Exit(err, 1)
+	}
+	if file, err = openInput(c); err != nil {
+		return nil, nil, err
+	}
+	return file, matcher, nil
+}
+
+// openInput opens the file the "path" flag gives, or returns stdin if empty.
+// The error is to be returned from the action as is.
+func openInput(c *cli.Command) (*os.File, error) {
+	filePath := c.String("path")
+	if filePath == "" {
+		return os.Stdin, nil
+	}
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, cli.Exit(fmt.Errorf("read from file: %w", err), 1)
+	}
+	return file, nil
+}
+
+// newMatcher returns the matcher of the position given by the flags.
+func newMatcher(c *cli.Command, lineFlag, colFlag, offsetFlag string) (dmatcher.NodeMatcher, error) {
+	if c.IsSet(offsetFlag) {
+		return dmatcher.NewNodeMatcherByOffset(int(c.Uint(offsetFlag)), dmatcher.ColumnUnit(c.String("offset-unit")), int(c.Uint("offset-base"))), nil
+	}
+
+	column, err := newColumn(c)
+	if err != nil {
+		return nil, err
+	}
+	line := c.Uint(lineFlag)
+	col := c.Uint(colFlag)
+	if !c.IsSet(colFlag) || (column.Base == 1 && col == 0) {
+		return dmatcher.NewNodeMatcherByLine(int(line)), nil
+	}
+	return dmatcher.NewNodeMatcherByLineAndColIn(int(line), int(col), column), nil
+}
+
+func locateCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "locate",
+		ArgsUsage: "PATH",
+		Usage:     "Reads yaml and output line:col of the node corresponding to the given path",
+		Flags: append([]cli.Flag{
+			&cli.BoolFlag{
+				Name:  "document",
+				Usage: "prefix output with the index of the document containing the node",
+			},
+		}, columnFlags()...),
+		Action: func(ctx context.Context, c *cli.Command) error {
+			if c.Args().Len() != 1 {
+				return cli.Exit("exactly one path is required", 1)
+			}
+			strpath := c.Args().First()
+			format := c.String("format")
+
+			var parser ppath.PathParser
+			switch format {
+			case "bosh":
+				parser = &ppath.PathParserBosh{
+					Separator: c.String("bosh.sep"),
+					NameAttr:  c.String("bosh.name"),
+				}
+			case "jsonpath":
+				parser = &ppath.PathParserJSONPath{}
+			case "jsonpointer":
+				parser = &ppath.PathParserJSONPointer{}
+			default:
+				return cli.Exit(fmt.Errorf("unsupported path format: %s", format), 1)
+			}
+
+			column, err := newColumn(c)
+			if err != nil {
+				return cli.Exit(err, 1)
+			}
+			file, err := openInput(c)
+			if err != nil {
+				return err
+			}
+
+			location, err := ppath.NewLocationIn(file, strpath, parser, column)
+			if err != nil {
+				return cli.Exit(fmt.Errorf("locate path: %w", err), 1)
+			}
+			if c.Bool("document") {
+				fmt.Printf("%d:", location.Document)
+			}
+			fmt.Printf("%d:%d\n", location.Line, location.Column)
+
+			return nil
+		},
+	}
+}
+
+func extentCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "extent",
+		ArgsUsage: "--line uint",
+		Usage:     "Reads yaml and output the ranges of the node at the cursor and its ancestors, as \"PATH<TAB>START-END<TAB>VALUE_START-VALUE_END\" from the node to the root",
+		Description: "START-END is the range including the key of mapping value and the dash of sequence item, and VALUE_START-VALUE_END is the range of the node itself. " +
+			"Each position is line:col, col is counted as the cursor column, and END is exclusive.",
+		Flags: cursorFlags(),
+		Action: func(ctx context.Context, c *cli.Command) error {
+			file, matcher, err := cursorInput(c)
+			if err != nil {
+				return err
+			}
+
+			formatter, err := newFormatter(c)
+			if err != nil {
+				return cli.Exit(err, 1)
+			}
+			column, err := newColumn(c)
+			if err != nil {
+				return cli.Exit(err, 1)
+			}
+
+			extents, err := ppath.NewExtents(file, matcher, column)
+			if err != nil {
+				return cli.Exit(fmt.Errorf("resolve extents: %w", err), 1)
+			}
+			for _, extent := range extents {
+				strpath, err := extent.Path.ToString(formatter)
+				if err != nil {
+					return cli.Exit(fmt.Errorf("path formatting error: %w", err), 1)
+				}
+				fmt.Printf("%s\t%s\t%s\n", strpath, formatSpan(extent.Span), formatSpan(extent.ValueSpan))
+			}
+
+			return nil
+		},
+	}
+}
+
+func formatSpan(span source.Span) string {
+	return fmt.Sprintf("%d:%d-%d:%d", span.Start.Line, span.Start.Column, span.End.Line, span.End.Column)
+}
+
+func opsCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "ops",
+		ArgsUsage: "--line uint",
+		Usage:     "Reads yaml and output BOSH ops-file operation for the node at the cursor",
+		Flags: append(cursorFlags(),
+			&cli.StringFlag{
+				Name:  "type",
+				Usage: `operation type. "replace" or "remove"`,
+				Value: "replace",
+			},
+		),
+		Action: func(ctx context.Context, c *cli.Command) error {
+			file, matcher, err := cursorInput(c)
+			if err != nil {
+				return err
+			}
+
+			options, err := formatterOptions(c)
+			if err != nil {
+				return cli.Exit(err, 1)
+			}
+			formatter, err := ppath.NewBoshOperationFormatter(options)
+			if err != nil {
+				return cli.Exit(err, 1)
+			}
+
+			path, err := ppath.NewEnclosingPath(file, matcher)
+			if err != nil {
+				return cli.Exit(fmt.Errorf("resolve path: %w", err), 1)
+			}
+			operation, err := ppath.NewBoshOperation(path, formatter, c.String("type"))
+			if err != nil {
+				return cli.Exit(fmt.Errorf("create operation: %w", err), 1)
+			}
+			data, err := operation.Marshal()
+			if err != nil {
+				return cli.Exit(fmt.Errorf("marshal operation: %w", err), 1)
+			}
+			fmt.Print(string(data))
+
+			return nil
+		},
+	}
+}
+
+func patchCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "patch",
+		ArgsUsage: "--line uint",
+		Usage:     "Reads yaml and output JSON Patch document for the node at the cursor",
+		Flags: append(cursorFlags(),
+			&cli.StringFlag{
+				Name:  "op",
+				Usage: `operation. "replace", "remove", "add" or "test"`,
+				Value: "replace",
+			},
+			&cli.StringFlag{
+				Name:  "output",
+				Usage: `output format of the document. "json" or "yaml"`,
+				Value: "json",
+			},
+		),
+		Action: func(ctx context.Context, c *cli.Command) error {
+			file, matcher, err := cursorInput(c)
+			if err != nil {
+				return err
+			}
+			output := c.String("output")
+			if output != "json" && output != "yaml" {
+				return cli.Exit(fmt.Errorf("unsupported output format: %s", output), 1)
+			}
+
+			path, err := ppath.NewEnclosingPath(file, matcher)
+			if err != nil {
+				return cli.Exit(fmt.Errorf("resolve path: %w", err), 1)
+			}
+			operation, err := ppath.NewJSONPatchOperation(path, c.String("op"))
+			if err != nil {
+				return cli.Exit(fmt.Errorf("create operation: %w", err), 1)
+			}
+			var data []byte
+			if output == "yaml" {
+				data, err = operation.Marshal()
+			} else {
+				// json.MarshalIndent escapes HTML characters in the values
+				var buf bytes.Buffer
+				encoder := json.NewEncoder(&buf)
+				encoder.SetEscapeHTML(false)
+				encoder.SetIndent("", "  ")
+				err = encoder.Encode([]*ppath.JSONPatchOperation{operation})
+				data = buf.Bytes()
+			}
+			if err != nil {
+				return cli.Exit(fmt.Errorf("marshal operation: %w", err), 1)
+			}
+			fmt.Print(string(data))
+
+			return nil
+		},
+	}
+}
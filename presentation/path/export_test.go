@@ -0,0 +1,9 @@
+package path
+
+// UnregisterFormatter removes the formatter registered by RegisterFormatter,
+// so that tests can register the same name again.
+func UnregisterFormatter(name string) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	delete(registry, name)
+}
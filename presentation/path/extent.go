@@ -0,0 +1,63 @@
+package path
+
+import (
+	"io"
+
+	dmatcher "github.com/gidoichi/yaml-path/domain/matcher"
+	"github.com/gidoichi/yaml-path/domain/source"
+	dyaml "github.com/gidoichi/yaml-path/domain/yaml"
+)
+
+// Extent is the range of a node in the path, written in the source. The end
+// positions are exclusive.
+type Extent struct {
+	// Path is the path from the root to the node.
+	Path *Path
+	// Span is the range including the key of mapping value and the dash of
+	// sequence item. ValueSpan is the range of the node itself.
+	Span      source.Span
+	ValueSpan source.Span
+}
+
+// NewExtents returns the extents of the node at the point and its ancestors,
+// from the node to the root. Columns are counted in the given column.
+func NewExtents(in io.Reader, matcher dmatcher.NodeMatcher, column dmatcher.Column) (extents []Extent, err error) {
+	yaml, err := dyaml.NewStream(in)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := yaml.PathEnclosingPoint(matcher)
+	if err != nil {
+		return nil, err
+	}
+	segments, err := yaml.Segments(p)
+	if err != nil {
+		return nil, err
+	}
+
+	src := yaml.Source()
+	for i := len(segments) - 1; i >= 0; i-- {
+		extents = append(extents, Extent{
+			Path:      NewPathFromSegments(segments[:i+1]),
+			Span:      convertSpan(src, segments[i].Span, column),
+			ValueSpan: convertSpan(src, segments[i].ValueSpan, column),
+		})
+	}
+	return extents, nil
+}
+
+func convertSpan(src *source.Source, span source.Span, column dmatcher.Column) source.Span {
+	return source.Span{
+		Start: convertPosition(src, span.Start, column),
+		End:   convertPosition(src, span.End, column),
+	}
+}
+
+func convertPosition(src *source.Source, pos source.Position, column dmatcher.Column) source.Position {
+	text, _ := src.Line(pos.Line)
+	return source.Position{
+		Line:   pos.Line,
+		Column: column.FromRuneColumn(text, pos.Column),
+	}
+}
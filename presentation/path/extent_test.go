@@ -0,0 +1,54 @@
+package path_test
+
+import (
+	"bytes"
+
+	dmatcher "github.com/gidoichi/yaml-path/domain/matcher"
+	"github.com/gidoichi/yaml-path/domain/source"
+	ppath "github.com/gidoichi/yaml-path/presentation/path"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Extent", func() {
+	data := []byte(`top:
+  - name: あい
+    attr: val
+`)
+
+	Describe("NewExtents()", func() {
+		It("should return the extents from the node to the root", func() {
+			extents, err := ppath.NewExtents(bytes.NewReader(data), dmatcher.NewNodeMatcherByLineAndCol(2, 12), dmatcher.DefaultColumn)
+			Expect(err).NotTo(HaveOccurred())
+
+			formatter := &ppath.PathFormatterJSONPath{}
+			var paths []string
+			for _, extent := range extents {
+				strpath, err := extent.Path.ToString(formatter)
+				Expect(err).NotTo(HaveOccurred())
+				paths = append(paths, strpath)
+			}
+			Expect(paths).To(Equal([]string{"$.top[0].name", "$.top[0]", "$.top", "$"}))
+			Expect(extents[0].Span).To(Equal(span(2, 5, 2, 13)))
+			Expect(extents[0].ValueSpan).To(Equal(span(2, 11, 2, 13)))
+			Expect(extents[1].Span).To(Equal(span(2, 3, 3, 14)))
+			Expect(extents[1].ValueSpan).To(Equal(span(2, 5, 3, 14)))
+		})
+
+		It("should count columns in the given unit", func() {
+			column := dmatcher.Column{Unit: dmatcher.ColumnByte, Base: 0}
+			extents, err := ppath.NewExtents(bytes.NewReader(data), dmatcher.NewNodeMatcherByLineAndColIn(2, 13, column), column)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(extents[0].Span).To(Equal(span(2, 4, 2, 16)))
+			Expect(extents[0].ValueSpan).To(Equal(span(2, 10, 2, 16)))
+		})
+	})
+})
+
+func span(startLine, startCol, endLine, endCol int) source.Span {
+	return source.Span{
+		Start: source.Position{Line: startLine, Column: startCol},
+		End:   source.Position{Line: endLine, Column: endCol},
+	}
+}
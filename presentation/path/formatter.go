@@ -1,10 +1,14 @@
 package path
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
 
+	dyaml "github.com/gidoichi/yaml-path/domain/yaml"
 	yamlv3 "gopkg.in/yaml.v3"
 )
 
@@ -18,72 +22,459 @@ type PathFormatterBosh struct {
 }
 
 func (f *PathFormatterBosh) ToString(path *Path) (strpath string, err error) {
+	segments, err := path.Segments()
+	if err != nil {
+		return "", fmt.Errorf("get segments: %w", err)
+	}
+
 	var builder strings.Builder
-	for i := 0; i < path.Len(); i++ {
-		node, err := path.Get(i)
-		if err != nil {
-			return "", fmt.Errorf("get node: %w", err)
+	for _, segment := range segments {
+		switch segment.Kind {
+		case dyaml.DocumentSegment:
+			continue
+		case dyaml.KeySegment:
+			builder.WriteString(f.Separator + segment.Key)
+		case dyaml.IndexSegment, dyaml.SelectorSegment:
+			if name := segment.Selection(f.NameAttr); name != "" {
+				builder.WriteString(f.Separator + f.NameAttr + "=" + name)
+				continue
+			}
+			if segment.Index < 0 {
+				return "", fmt.Errorf("unknown index: %s", segment)
+			}
+			builder.WriteString(f.Separator + strconv.Itoa(segment.Index))
+		default:
+			return "", fmt.Errorf("invalid path: %s", path)
 		}
-		switch node.Kind {
-		case yamlv3.SequenceNode:
-			i++
-			next, err := path.Get(i)
-			if err != nil {
-				return "", fmt.Errorf("get node: %w", err)
+	}
+
+	return builder.String(), nil
+}
+
+// PathFormatterJSONPath formats the path as JSONPath defined in RFC 9535.
+// Keys which cannot be written in shorthand are written in bracket notation,
+// e.g. `$.metadata.labels['app.kubernetes.io/name']`. When NameAttr is set,
+// sequence items are selected by filter selector with the value of the
+// attribute if it is unique, e.g. `$.top.first[?@.name=='myname'].attr2`.
+type PathFormatterJSONPath struct {
+	NameAttr string
+}
+
+func (f *PathFormatterJSONPath) ToString(path *Path) (strpath string, err error) {
+	segments, err := path.Segments()
+	if err != nil {
+		return "", fmt.Errorf("get segments: %w", err)
+	}
+
+	var builder strings.Builder
+	for _, segment := range segments {
+		switch segment.Kind {
+		case dyaml.DocumentSegment:
+			builder.WriteString("$")
+		case dyaml.KeySegment:
+			builder.WriteString(jsonPathMember(segment.Key))
+		case dyaml.IndexSegment, dyaml.SelectorSegment:
+			if f.NameAttr != "" {
+				if name := segment.Selection(f.NameAttr); name != "" {
+					builder.WriteString("[?@" + jsonPathMember(f.NameAttr) + "==" + jsonPathQuote(name) + "]")
+					continue
+				}
 			}
-			seqidx, err := strconv.ParseInt(next.Value, 10, 0)
-			if err != nil {
-				return "", fmt.Errorf("invalid number: %w", err)
+			if segment.Index < 0 {
+				return "", fmt.Errorf("unknown index: %s", segment)
 			}
+			builder.WriteString("[" + strconv.Itoa(segment.Index) + "]")
+		default:
+			return "", fmt.Errorf("invalid path: %s", path)
+		}
+	}
 
-			if name := node.FindSequenceSelectionByMappingKey(int(seqidx), f.NameAttr); name != "" {
-				builder.WriteString(f.Separator + f.NameAttr + "=" + name)
+	return builder.String(), nil
+}
+
+// jsonPathShorthandPattern matches member names which can be written in
+// shorthand notation of JSONPath.
+var jsonPathShorthandPattern = regexp.MustCompile(`^[A-Za-z_\x{80}-\x{D7FF}\x{E000}-\x{10FFFF}][0-9A-Za-z_\x{80}-\x{D7FF}\x{E000}-\x{10FFFF}]*$`)
+
+// jsonPathMember returns the child segment of JSONPath selecting the member.
+func jsonPathMember(name string) string {
+	if jsonPathShorthandPattern.MatchString(name) {
+		return "." + name
+	}
+	return "[" + jsonPathQuote(name) + "]"
+}
+
+// jsonPathQuote quotes the string as single-quoted string literal of JSONPath.
+func jsonPathQuote(s string) string {
+	var builder strings.Builder
+	builder.WriteByte('\'')
+	for _, r := range s {
+		switch r {
+		case '\\', '\'':
+			builder.WriteRune('\\')
+			builder.WriteRune(r)
+		case '\b':
+			builder.WriteString(`\b`)
+		case '\f':
+			builder.WriteString(`\f`)
+		case '\n':
+			builder.WriteString(`\n`)
+		case '\r':
+			builder.WriteString(`\r`)
+		case '\t':
+			builder.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(&builder, `\u%04x`, r)
 				continue
 			}
-			builder.WriteString(f.Separator + next.Value)
-		case yamlv3.MappingNode:
-			i++
-			next, err := path.Get(i)
-			if err != nil {
-				return "", fmt.Errorf("get node: %w", err)
+			builder.WriteRune(r)
+		}
+	}
+	builder.WriteByte('\'')
+	return builder.String()
+}
+
+// PathFormatterJSONPointer formats the path as JSON Pointer defined in RFC
+// 6901. Sequence items are always indicated by the index.
+type PathFormatterJSONPointer struct{}
+
+var jsonPointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+func (f *PathFormatterJSONPointer) ToString(path *Path) (strpath string, err error) {
+	segments, err := path.Segments()
+	if err != nil {
+		return "", fmt.Errorf("get segments: %w", err)
+	}
+
+	var builder strings.Builder
+	for _, segment := range segments {
+		switch segment.Kind {
+		case dyaml.DocumentSegment:
+			continue
+		case dyaml.KeySegment:
+			builder.WriteString("/" + jsonPointerEscaper.Replace(segment.Key))
+		case dyaml.IndexSegment, dyaml.SelectorSegment:
+			if segment.Index < 0 {
+				return "", fmt.Errorf("unknown index: %s", segment)
 			}
-			builder.WriteString(f.Separator + next.Value)
-		case yamlv3.DocumentNode, yamlv3.ScalarNode, yamlv3.AliasNode:
+			builder.WriteString("/" + strconv.Itoa(segment.Index))
+		default:
+			return "", fmt.Errorf("invalid path: %s", path)
+		}
+	}
+
+	return builder.String(), nil
+}
+
+// PathFormatterYq formats the path as an expression of mikefarah/yq. When
+// NameAttr is set, sequence items are selected by the value of the attribute
+// if it is unique, e.g. `.top.first[] | select(.name == "myname") | .attr2`.
+type PathFormatterYq struct {
+	NameAttr string
+}
+
+func (f *PathFormatterYq) ToString(path *Path) (strpath string, err error) {
+	segments, err := path.Segments()
+	if err != nil {
+		return "", fmt.Errorf("get segments: %w", err)
+	}
+
+	var builder strings.Builder
+	piped := false
+	write := func(token string) {
+		// bracket needs a leading dot at the beginning of the expression
+		head := builder.Len() == 0 || piped
+		if piped {
+			builder.WriteString(" | ")
+			piped = false
+		}
+		if head && strings.HasPrefix(token, "[") {
+			builder.WriteString(".")
+		}
+		builder.WriteString(token)
+	}
+	for _, segment := range segments {
+		switch segment.Kind {
+		case dyaml.DocumentSegment:
 			continue
+		case dyaml.KeySegment:
+			write(yqField(segment.Key))
+		case dyaml.IndexSegment, dyaml.SelectorSegment:
+			if f.NameAttr != "" {
+				if name := segment.Selection(f.NameAttr); name != "" {
+					write("[] | select(" + yqField(f.NameAttr) + " == " + yqQuote(name) + ")")
+					piped = true
+					continue
+				}
+			}
+			if segment.Index < 0 {
+				return "", fmt.Errorf("unknown index: %s", segment)
+			}
+			write("[" + strconv.Itoa(segment.Index) + "]")
 		default:
 			return "", fmt.Errorf("invalid path: %s", path)
 		}
 	}
 
+	if builder.Len() == 0 {
+		return ".", nil
+	}
 	return builder.String(), nil
 }
 
-type PathFormatterJSONPath struct{}
+// PathFormatterKustomize formats the path as fieldPath of Kustomize, e.g.
+// `spec.template.spec.containers.[name=app].image`. Sequence items are
+// selected by the value of NameAttr if it is unique, and keys containing dots
+// are enclosed in brackets.
+type PathFormatterKustomize struct {
+	NameAttr string
+}
+
+func (f *PathFormatterKustomize) ToString(path *Path) (strpath string, err error) {
+	segments, err := path.Segments()
+	if err != nil {
+		return "", fmt.Errorf("get segments: %w", err)
+	}
+
+	var fields []string
+	for _, segment := range segments {
+		switch segment.Kind {
+		case dyaml.DocumentSegment:
+			continue
+		case dyaml.KeySegment:
+			if strings.Contains(segment.Key, ".") {
+				fields = append(fields, "["+segment.Key+"]")
+			} else {
+				fields = append(fields, segment.Key)
+			}
+		case dyaml.IndexSegment, dyaml.SelectorSegment:
+			if f.NameAttr != "" {
+				if name := segment.Selection(f.NameAttr); name != "" {
+					fields = append(fields, "["+f.NameAttr+"="+name+"]")
+					continue
+				}
+			}
+			if segment.Index < 0 {
+				return "", fmt.Errorf("unknown index: %s", segment)
+			}
+			fields = append(fields, strconv.Itoa(segment.Index))
+		default:
+			return "", fmt.Errorf("invalid path: %s", path)
+		}
+	}
+
+	return strings.Join(fields, "."), nil
+}
+
+// PathFormatterHelm formats the path as the key of `helm install --set`, e.g.
+// `top.first[0].attr2`. Characters having meaning in the key are escaped with
+// backslash.
+type PathFormatterHelm struct{}
+
+var helmKeyEscaper = strings.NewReplacer(`\`, `\\`, ".", `\.`, ",", `\,`, "=", `\=`, "[", `\[`)
+
+func (f *PathFormatterHelm) ToString(path *Path) (strpath string, err error) {
+	segments, err := path.Segments()
+	if err != nil {
+		return "", fmt.Errorf("get segments: %w", err)
+	}
 
-func (f *PathFormatterJSONPath) ToString(path *Path) (strpath string, err error) {
 	var builder strings.Builder
-	for i := 0; i < path.Len(); i++ {
-		node, err := path.Get(i)
-		if err != nil {
-			return "", fmt.Errorf("get node: %w", err)
+	for _, segment := range segments {
+		switch segment.Kind {
+		case dyaml.DocumentSegment:
+			continue
+		case dyaml.KeySegment:
+			if builder.Len() > 0 {
+				builder.WriteString(".")
+			}
+			builder.WriteString(helmKeyEscaper.Replace(segment.Key))
+		case dyaml.IndexSegment, dyaml.SelectorSegment:
+			if builder.Len() == 0 {
+				return "", fmt.Errorf("index of root is not supported: %s", segment)
+			}
+			if segment.Index < 0 {
+				return "", fmt.Errorf("unknown index: %s", segment)
+			}
+			builder.WriteString("[" + strconv.Itoa(segment.Index) + "]")
+		default:
+			return "", fmt.Errorf("invalid path: %s", path)
 		}
-		switch node.Kind {
-		case yamlv3.DocumentNode:
-			builder.WriteString("$")
-		case yamlv3.SequenceNode:
-			next, err := path.Get(i + 1)
-			if err != nil {
-				return "", fmt.Errorf("get node: %w", err)
-			}
-			builder.WriteString("[" + next.Value + "]")
-		case yamlv3.MappingNode:
-			next, err := path.Get(i + 1)
-			if err != nil {
-				return "", fmt.Errorf("get node: %w", err)
-			}
-			builder.WriteString("." + next.Value)
-		case yamlv3.ScalarNode, yamlv3.AliasNode:
+	}
+
+	return builder.String(), nil
+}
+
+// IndexStyle is the way to write the index of sequence item.
+type IndexStyle string
+
+const (
+	// IndexStyleBracket writes the index in brackets, e.g. `first[0]`.
+	IndexStyleBracket IndexStyle = "bracket"
+	// IndexStyleSeparator writes the index as a key, e.g. `first.0`.
+	IndexStyleSeparator IndexStyle = "separator"
+	// IndexStyleNone omits the index.
+	IndexStyleNone IndexStyle = "none"
+)
+
+// PathFormatterProperties formats the path as the key of properties, e.g.
+// `top.first[0].attr2`. Keys containing the separator are enclosed in
+// brackets as Spring Boot binds map keys. The zero value uses "." as the
+// separator and IndexStyleBracket.
+type PathFormatterProperties struct {
+	Separator  string
+	IndexStyle IndexStyle
+}
+
+func (f *PathFormatterProperties) ToString(path *Path) (strpath string, err error) {
+	segments, err := path.Segments()
+	if err != nil {
+		return "", fmt.Errorf("get segments: %w", err)
+	}
+	separator := f.Separator
+	if separator == "" {
+		separator = "."
+	}
+
+	var builder strings.Builder
+	for _, segment := range segments {
+		switch segment.Kind {
+		case dyaml.DocumentSegment:
+			continue
+		case dyaml.KeySegment:
+			if strings.Contains(segment.Key, separator) {
+				builder.WriteString("[" + segment.Key + "]")
+				continue
+			}
+			if builder.Len() > 0 {
+				builder.WriteString(separator)
+			}
+			builder.WriteString(segment.Key)
+		case dyaml.IndexSegment, dyaml.SelectorSegment:
+			if segment.Index < 0 {
+				return "", fmt.Errorf("unknown index: %s", segment)
+			}
+			switch f.IndexStyle {
+			case IndexStyleBracket, "":
+				builder.WriteString("[" + strconv.Itoa(segment.Index) + "]")
+			case IndexStyleSeparator:
+				if builder.Len() > 0 {
+					builder.WriteString(separator)
+				}
+				builder.WriteString(strconv.Itoa(segment.Index))
+			default:
+				return "", fmt.Errorf("unsupported index style: %s", f.IndexStyle)
+			}
+		default:
+			return "", fmt.Errorf("invalid path: %s", path)
+		}
+	}
+
+	return builder.String(), nil
+}
+
+// Case is the case conversion of the keys.
+type Case string
+
+const (
+	CaseUpper    Case = "upper"
+	CaseLower    Case = "lower"
+	CasePreserve Case = "preserve"
+)
+
+// PathFormatterEnv formats the path as the name of environment variable, e.g.
+// `TOP_FIRST_0_ATTR2`. Characters not allowed in the name are replaced with
+// underscore. The zero value uses "_" as the separator, CaseUpper and
+// IndexStyleSeparator.
+type PathFormatterEnv struct {
+	// Prefix is prepended with the separator if not empty, as is.
+	Prefix     string
+	Separator  string
+	Case       Case
+	IndexStyle IndexStyle
+}
+
+var envInvalidPattern = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+func (f *PathFormatterEnv) ToString(path *Path) (strpath string, err error) {
+	segments, err := path.Segments()
+	if err != nil {
+		return "", fmt.Errorf("get segments: %w", err)
+	}
+	separator := f.Separator
+	if separator == "" {
+		separator = "_"
+	}
+
+	var names []string
+	if f.Prefix != "" {
+		names = append(names, f.Prefix)
+	}
+	for _, segment := range segments {
+		switch segment.Kind {
+		case dyaml.DocumentSegment:
+			continue
+		case dyaml.KeySegment:
+			name := envInvalidPattern.ReplaceAllString(segment.Key, "_")
+			switch f.Case {
+			case CaseUpper, "":
+				name = strings.ToUpper(name)
+			case CaseLower:
+				name = strings.ToLower(name)
+			case CasePreserve:
+			default:
+				return "", fmt.Errorf("unsupported case: %s", f.Case)
+			}
+			names = append(names, name)
+		case dyaml.IndexSegment, dyaml.SelectorSegment:
+			switch f.IndexStyle {
+			case IndexStyleSeparator, "":
+				if segment.Index < 0 {
+					return "", fmt.Errorf("unknown index: %s", segment)
+				}
+				names = append(names, strconv.Itoa(segment.Index))
+			case IndexStyleNone:
+			default:
+				return "", fmt.Errorf("unsupported index style: %s", f.IndexStyle)
+			}
+		default:
+			return "", fmt.Errorf("invalid path: %s", path)
+		}
+	}
+
+	return strings.Join(names, separator), nil
+}
+
+// PathFormatterTerraform formats the path as an expression of Terraform
+// indexing the value which yamldecode returns, e.g.
+// `local.cfg["top"]["first"][0]["attr2"]`.
+type PathFormatterTerraform struct {
+	// Root is the expression of the decoded document.
+	Root string
+}
+
+var hclEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`, "${", "$${", "%{", "%%{")
+
+func (f *PathFormatterTerraform) ToString(path *Path) (strpath string, err error) {
+	segments, err := path.Segments()
+	if err != nil {
+		return "", fmt.Errorf("get segments: %w", err)
+	}
+
+	var builder strings.Builder
+	builder.WriteString(f.Root)
+	for _, segment := range segments {
+		switch segment.Kind {
+		case dyaml.DocumentSegment:
 			continue
+		case dyaml.KeySegment:
+			builder.WriteString(`["` + hclEscaper.Replace(segment.Key) + `"]`)
+		case dyaml.IndexSegment, dyaml.SelectorSegment:
+			if segment.Index < 0 {
+				return "", fmt.Errorf("unknown index: %s", segment)
+			}
+			builder.WriteString("[" + strconv.Itoa(segment.Index) + "]")
 		default:
 			return "", fmt.Errorf("invalid path: %s", path)
 		}
@@ -91,3 +482,237 @@ func (f *PathFormatterJSONPath) ToString(path *Path) (strpath string, err error)
 
 	return builder.String(), nil
 }
+
+// PathFormatterJq formats the path as a path expression of jq. When NameAttr
+// is set, sequence items are selected by the value of the attribute if it is
+// unique, e.g. `.top.first | (.[] | select(.name == "myname")).attr2`.
+type PathFormatterJq struct {
+	NameAttr string
+}
+
+func (f *PathFormatterJq) ToString(path *Path) (strpath string, err error) {
+	segments, err := path.Segments()
+	if err != nil {
+		return "", fmt.Errorf("get segments: %w", err)
+	}
+
+	var builder strings.Builder
+	for _, segment := range segments {
+		switch segment.Kind {
+		case dyaml.DocumentSegment:
+			continue
+		case dyaml.KeySegment:
+			builder.WriteString(jqField(segment.Key))
+		case dyaml.IndexSegment, dyaml.SelectorSegment:
+			if f.NameAttr != "" {
+				if name := segment.Selection(f.NameAttr); name != "" {
+					if builder.Len() > 0 {
+						builder.WriteString(" | ")
+					}
+					builder.WriteString("(.[] | select(" + jqField(f.NameAttr) + " == " + jsonQuote(name) + "))")
+					continue
+				}
+			}
+			if segment.Index < 0 {
+				return "", fmt.Errorf("unknown index: %s", segment)
+			}
+			if builder.Len() == 0 {
+				builder.WriteString(".")
+			}
+			builder.WriteString("[" + strconv.Itoa(segment.Index) + "]")
+		default:
+			return "", fmt.Errorf("invalid path: %s", path)
+		}
+	}
+
+	if builder.Len() == 0 {
+		return ".", nil
+	}
+	return builder.String(), nil
+}
+
+// jqField returns the field access of jq.
+func jqField(key string) string {
+	if identifierPattern.MatchString(key) {
+		return "." + key
+	}
+	return "." + jsonQuote(key)
+}
+
+// jsonQuote quotes the string as JSON string, which is also string literal of
+// jq, Python and JavaScript.
+func jsonQuote(s string) string {
+	// encoding string never fails
+	data, _ := jsonEncode(s)
+	return string(data)
+}
+
+// jsonEncode encodes the value in JSON without escaping HTML characters,
+// which json.Marshal does.
+func jsonEncode(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// PathFormatterSubscript formats the path as subscripts of Python or
+// JavaScript to the value which the yaml is loaded into, e.g.
+// `data["top"]["first"][0]["attr2"]`.
+type PathFormatterSubscript struct {
+	// Root is the expression of the loaded document.
+	Root string
+}
+
+func (f *PathFormatterSubscript) ToString(path *Path) (strpath string, err error) {
+	segments, err := path.Segments()
+	if err != nil {
+		return "", fmt.Errorf("get segments: %w", err)
+	}
+
+	var builder strings.Builder
+	builder.WriteString(f.Root)
+	for _, segment := range segments {
+		switch segment.Kind {
+		case dyaml.DocumentSegment:
+			continue
+		case dyaml.KeySegment:
+			builder.WriteString("[" + jsonQuote(segment.Key) + "]")
+		case dyaml.IndexSegment, dyaml.SelectorSegment:
+			if segment.Index < 0 {
+				return "", fmt.Errorf("unknown index: %s", segment)
+			}
+			builder.WriteString("[" + strconv.Itoa(segment.Index) + "]")
+		default:
+			return "", fmt.Errorf("invalid path: %s", path)
+		}
+	}
+
+	return builder.String(), nil
+}
+
+// PathFormatterGoTemplate formats the path as an action of Go template, e.g.
+// `{{ .Values.top.first }}`. Keys are written as field chain while possible,
+// and the rest is given to index function, e.g.
+// `{{ index .Values.top.first 0 "attr2" }}`.
+type PathFormatterGoTemplate struct {
+	// Root is the field chain of the document, e.g. ".Values" for Helm.
+	Root string
+}
+
+func (f *PathFormatterGoTemplate) ToString(path *Path) (strpath string, err error) {
+	segments, err := path.Segments()
+	if err != nil {
+		return "", fmt.Errorf("get segments: %w", err)
+	}
+
+	chain := f.Root
+	var args []string
+	for _, segment := range segments {
+		switch segment.Kind {
+		case dyaml.DocumentSegment:
+			continue
+		case dyaml.KeySegment:
+			if args == nil && identifierPattern.MatchString(segment.Key) {
+				chain += "." + segment.Key
+				continue
+			}
+			args = append(args, strconv.Quote(segment.Key))
+		case dyaml.IndexSegment, dyaml.SelectorSegment:
+			if segment.Index < 0 {
+				return "", fmt.Errorf("unknown index: %s", segment)
+			}
+			args = append(args, strconv.Itoa(segment.Index))
+		default:
+			return "", fmt.Errorf("invalid path: %s", path)
+		}
+	}
+
+	if chain == "" {
+		chain = "."
+	}
+	if args == nil {
+		return "{{ " + chain + " }}", nil
+	}
+	return "{{ index " + chain + " " + strings.Join(args, " ") + " }}", nil
+}
+
+// PathFormatterKubectlExplain formats the path as the argument of `kubectl
+// explain`, e.g. `deployment.spec.template.spec.containers.image`. The
+// resource is the lowercased `kind` of the document, and sequence items are
+// omitted because the items share the schema.
+type PathFormatterKubectlExplain struct {
+	// Kind is used instead of the kind of the document if not empty.
+	Kind string
+}
+
+func (f *PathFormatterKubectlExplain) ToString(path *Path) (strpath string, err error) {
+	segments, err := path.Segments()
+	if err != nil {
+		return "", fmt.Errorf("get segments: %w", err)
+	}
+
+	kind := f.Kind
+	fields := []string{""}
+	for _, segment := range segments {
+		switch segment.Kind {
+		case dyaml.DocumentSegment:
+			if kind == "" {
+				kind = documentKind(segment.Node)
+			}
+		case dyaml.KeySegment:
+			fields = append(fields, segment.Key)
+		case dyaml.IndexSegment, dyaml.SelectorSegment:
+			continue
+		default:
+			return "", fmt.Errorf("invalid path: %s", path)
+		}
+	}
+	if kind == "" {
+		return "", fmt.Errorf("kind not found: %s", path)
+	}
+	fields[0] = strings.ToLower(kind)
+
+	return strings.Join(fields, "."), nil
+}
+
+// documentKind returns the value of `kind` in the top-level mapping of the
+// document.
+func documentKind(document *yamlv3.Node) string {
+	if document == nil || len(document.Content) == 0 {
+		return ""
+	}
+	root := document.Content[0]
+	if root.Kind != yamlv3.MappingNode {
+		return ""
+	}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == "kind" && root.Content[i+1].Kind == yamlv3.ScalarNode {
+			return root.Content[i+1].Value
+		}
+	}
+	return ""
+}
+
+// identifierPattern matches keys which can be written without quotes.
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// yqField returns the field access of yq.
+func yqField(key string) string {
+	if identifierPattern.MatchString(key) {
+		return "." + key
+	}
+	return ".[" + yqQuote(key) + "]"
+}
+
+// yqEscaper escapes backslashes and double quotes, which end string literal
+// of yq.
+var yqEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// yqQuote quotes the string as string literal of yq.
+func yqQuote(s string) string {
+	return `"` + yqEscaper.Replace(s) + `"`
+}
@@ -154,6 +154,448 @@ var _ = Describe("Path", func() {
 				Expect(err).NotTo(HaveOccurred())
 				Expect(strpath).To(Equal("$.top.first[0].attr2"))
 			})
+
+			It("should write keys in bracket notation if needed", func() {
+				path = ppath.NewPathFromSegments(dyaml.Segments{
+					dyaml.NewDocumentSegment(),
+					dyaml.NewKeySegment("metadata"),
+					dyaml.NewKeySegment("app.kubernetes.io/name"),
+					dyaml.NewKeySegment("with space"),
+					dyaml.NewKeySegment("0"),
+					dyaml.NewKeySegment("it's\n"),
+					dyaml.NewKeySegment("名前"),
+				})
+
+				Expect(path.ToString(formatter)).To(Equal(`$.metadata['app.kubernetes.io/name']['with space']['0']['it\'s\n'].名前`))
+			})
+
+			Context("with name attribute", func() {
+				BeforeEach(func() {
+					formatter = &ppath.PathFormatterJSONPath{
+						NameAttr: "name",
+					}
+				})
+
+				It("should select sequence item by filter selector", func() {
+					strpath, err := path.ToString(formatter)
+
+					Expect(err).NotTo(HaveOccurred())
+					Expect(strpath).To(Equal("$.top.first[?@.name=='myname'].attr2"))
+				})
+			})
+		})
+
+		Context("converting to jsonpointer format", func() {
+			BeforeEach(func() {
+				formatter = &ppath.PathFormatterJSONPointer{}
+			})
+
+			It("should convert to jsonpointer format", func() {
+				strpath, err := path.ToString(formatter)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(strpath).To(Equal("/top/first/0/attr2"))
+			})
+
+			It("should escape keys", func() {
+				path = ppath.NewPathFromSegments(dyaml.Segments{
+					dyaml.NewDocumentSegment(),
+					dyaml.NewKeySegment("app.kubernetes.io/name"),
+					dyaml.NewKeySegment("a~b"),
+				})
+
+				Expect(path.ToString(formatter)).To(Equal("/app.kubernetes.io~1name/a~0b"))
+			})
+
+			It("should convert root to empty string", func() {
+				path = ppath.NewPathFromSegments(dyaml.Segments{dyaml.NewDocumentSegment()})
+
+				Expect(path.ToString(formatter)).To(Equal(""))
+			})
+		})
+
+		Context("converting to yq format", func() {
+			BeforeEach(func() {
+				formatter = &ppath.PathFormatterYq{}
+			})
+
+			It("should convert to yq format", func() {
+				strpath, err := path.ToString(formatter)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(strpath).To(Equal(".top.first[0].attr2"))
+			})
+
+			It("should quote keys", func() {
+				path = ppath.NewPathFromSegments(dyaml.Segments{
+					dyaml.NewDocumentSegment(),
+					dyaml.NewKeySegment("key.with.dots"),
+					dyaml.NewKeySegment(`say "hi"`),
+				})
+
+				Expect(path.ToString(formatter)).To(Equal(`.["key.with.dots"].["say \"hi\""]`))
+			})
+
+			It("should escape backslashes in keys", func() {
+				path = ppath.NewPathFromSegments(dyaml.Segments{
+					dyaml.NewDocumentSegment(),
+					dyaml.NewKeySegment(`C:\`),
+				})
+
+				Expect(path.ToString(formatter)).To(Equal(`.["C:\\"]`))
+			})
+
+			It("should convert root to dot", func() {
+				path = ppath.NewPathFromSegments(dyaml.Segments{dyaml.NewDocumentSegment()})
+
+				Expect(path.ToString(formatter)).To(Equal("."))
+			})
+
+			Context("with name attribute", func() {
+				BeforeEach(func() {
+					formatter = &ppath.PathFormatterYq{
+						NameAttr: "name",
+					}
+				})
+
+				It("should select sequence item by name", func() {
+					strpath, err := path.ToString(formatter)
+
+					Expect(err).NotTo(HaveOccurred())
+					Expect(strpath).To(Equal(`.top.first[] | select(.name == "myname") | .attr2`))
+				})
+
+				It("should continue with index after selection", func() {
+					path = ppath.NewPathFromSegments(dyaml.Segments{
+						dyaml.NewDocumentSegment(),
+						dyaml.NewSelectorSegment("name", "myname"),
+						dyaml.NewIndexSegment(1),
+					})
+
+					Expect(path.ToString(formatter)).To(Equal(`.[] | select(.name == "myname") | .[1]`))
+				})
+
+				It("should quote name attribute not being identifier", func() {
+					formatter = &ppath.PathFormatterYq{
+						NameAttr: "the name",
+					}
+					path = ppath.NewPathFromSegments(dyaml.Segments{
+						dyaml.NewDocumentSegment(),
+						dyaml.NewSelectorSegment("the name", "myname"),
+					})
+
+					Expect(path.ToString(formatter)).To(Equal(`.[] | select(.["the name"] == "myname")`))
+				})
+			})
+		})
+
+		Context("converting to kustomize format", func() {
+			BeforeEach(func() {
+				formatter = &ppath.PathFormatterKustomize{
+					NameAttr: "name",
+				}
+			})
+
+			It("should convert to kustomize format with selector", func() {
+				strpath, err := path.ToString(formatter)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(strpath).To(Equal("top.first.[name=myname].attr2"))
+			})
+
+			It("should convert to kustomize format with index without name attribute", func() {
+				formatter = &ppath.PathFormatterKustomize{}
+
+				Expect(path.ToString(formatter)).To(Equal("top.first.0.attr2"))
+			})
+
+			It("should enclose keys containing dots in brackets", func() {
+				path = ppath.NewPathFromSegments(dyaml.Segments{
+					dyaml.NewDocumentSegment(),
+					dyaml.NewKeySegment("metadata"),
+					dyaml.NewKeySegment("annotations"),
+					dyaml.NewKeySegment("config.kubernetes.io/local-config"),
+				})
+
+				Expect(path.ToString(formatter)).To(Equal("metadata.annotations.[config.kubernetes.io/local-config]"))
+			})
+		})
+
+		Context("converting to helm format", func() {
+			BeforeEach(func() {
+				formatter = &ppath.PathFormatterHelm{}
+			})
+
+			It("should convert to helm format", func() {
+				strpath, err := path.ToString(formatter)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(strpath).To(Equal("top.first[0].attr2"))
+			})
+
+			It("should escape keys", func() {
+				path = ppath.NewPathFromSegments(dyaml.Segments{
+					dyaml.NewDocumentSegment(),
+					dyaml.NewKeySegment("podAnnotations"),
+					dyaml.NewKeySegment("app.kubernetes.io/name"),
+					dyaml.NewKeySegment(`a,b=c[d]\e`),
+				})
+
+				Expect(path.ToString(formatter)).To(Equal(`podAnnotations.app\.kubernetes\.io/name.a\,b\=c\[d]\\e`))
+			})
+
+			It("should fail with index of root", func() {
+				path = ppath.NewPathFromSegments(dyaml.Segments{
+					dyaml.NewDocumentSegment(),
+					dyaml.NewIndexSegment(0),
+				})
+
+				_, err := path.ToString(formatter)
+
+				Expect(err).To(HaveOccurred())
+			})
+		})
+
+		Context("converting to properties format", func() {
+			BeforeEach(func() {
+				formatter = &ppath.PathFormatterProperties{}
+			})
+
+			It("should convert to properties format", func() {
+				strpath, err := path.ToString(formatter)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(strpath).To(Equal("top.first[0].attr2"))
+			})
+
+			It("should enclose keys containing separator in brackets", func() {
+				path = ppath.NewPathFromSegments(dyaml.Segments{
+					dyaml.NewDocumentSegment(),
+					dyaml.NewKeySegment("labels"),
+					dyaml.NewKeySegment("app.kubernetes.io/name"),
+				})
+
+				Expect(path.ToString(formatter)).To(Equal("labels[app.kubernetes.io/name]"))
+			})
+
+			It("should convert with custom separator and index style", func() {
+				formatter = &ppath.PathFormatterProperties{
+					Separator:  ":",
+					IndexStyle: ppath.IndexStyleSeparator,
+				}
+
+				Expect(path.ToString(formatter)).To(Equal("top:first:0:attr2"))
+			})
+		})
+
+		Context("converting to env format", func() {
+			BeforeEach(func() {
+				formatter = &ppath.PathFormatterEnv{}
+			})
+
+			It("should convert to env format", func() {
+				strpath, err := path.ToString(formatter)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(strpath).To(Equal("TOP_FIRST_0_ATTR2"))
+			})
+
+			It("should replace invalid characters", func() {
+				path = ppath.NewPathFromSegments(dyaml.Segments{
+					dyaml.NewDocumentSegment(),
+					dyaml.NewKeySegment("server"),
+					dyaml.NewKeySegment("max-http.size"),
+				})
+
+				Expect(path.ToString(formatter)).To(Equal("SERVER_MAX_HTTP_SIZE"))
+			})
+
+			It("should convert with options", func() {
+				formatter = &ppath.PathFormatterEnv{
+					Prefix:     "APP",
+					Separator:  "__",
+					Case:       ppath.CaseLower,
+					IndexStyle: ppath.IndexStyleNone,
+				}
+
+				Expect(path.ToString(formatter)).To(Equal("APP__top__first__attr2"))
+			})
+		})
+
+		Context("converting to terraform format", func() {
+			BeforeEach(func() {
+				formatter = &ppath.PathFormatterTerraform{
+					Root: "local.cfg",
+				}
+			})
+
+			It("should convert to terraform format", func() {
+				strpath, err := path.ToString(formatter)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(strpath).To(Equal(`local.cfg["top"]["first"][0]["attr2"]`))
+			})
+
+			It("should escape keys", func() {
+				path = ppath.NewPathFromSegments(dyaml.Segments{
+					dyaml.NewDocumentSegment(),
+					dyaml.NewKeySegment(`say "hi"`),
+					dyaml.NewKeySegment("${var}"),
+				})
+
+				Expect(path.ToString(formatter)).To(Equal(`local.cfg["say \"hi\""]["$${var}"]`))
+			})
+		})
+
+		Context("converting to jq format", func() {
+			BeforeEach(func() {
+				formatter = &ppath.PathFormatterJq{}
+			})
+
+			It("should convert to jq format", func() {
+				strpath, err := path.ToString(formatter)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(strpath).To(Equal(".top.first[0].attr2"))
+			})
+
+			It("should quote keys", func() {
+				path = ppath.NewPathFromSegments(dyaml.Segments{
+					dyaml.NewDocumentSegment(),
+					dyaml.NewKeySegment("quoted key"),
+					dyaml.NewKeySegment(`\(x)`),
+				})
+
+				Expect(path.ToString(formatter)).To(Equal(`."quoted key"."\\(x)"`))
+			})
+
+			It("should convert index of root", func() {
+				path = ppath.NewPathFromSegments(dyaml.Segments{
+					dyaml.NewDocumentSegment(),
+					dyaml.NewIndexSegment(1),
+				})
+
+				Expect(path.ToString(formatter)).To(Equal(".[1]"))
+			})
+
+			Context("with name attribute", func() {
+				BeforeEach(func() {
+					formatter = &ppath.PathFormatterJq{
+						NameAttr: "name",
+					}
+				})
+
+				It("should select sequence item by name", func() {
+					strpath, err := path.ToString(formatter)
+
+					Expect(err).NotTo(HaveOccurred())
+					Expect(strpath).To(Equal(`.top.first | (.[] | select(.name == "myname")).attr2`))
+				})
+			})
+		})
+
+		Context("converting to subscript format", func() {
+			BeforeEach(func() {
+				formatter = &ppath.PathFormatterSubscript{
+					Root: "data",
+				}
+			})
+
+			It("should convert to subscript format", func() {
+				strpath, err := path.ToString(formatter)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(strpath).To(Equal(`data["top"]["first"][0]["attr2"]`))
+			})
+
+			It("should escape keys", func() {
+				path = ppath.NewPathFromSegments(dyaml.Segments{
+					dyaml.NewDocumentSegment(),
+					dyaml.NewKeySegment("say \"hi\"\n"),
+				})
+
+				Expect(path.ToString(formatter)).To(Equal(`data["say \"hi\"\n"]`))
+			})
+		})
+
+		Context("converting to gotemplate format", func() {
+			BeforeEach(func() {
+				formatter = &ppath.PathFormatterGoTemplate{}
+			})
+
+			It("should convert to gotemplate format with index function", func() {
+				strpath, err := path.ToString(formatter)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(strpath).To(Equal(`{{ index .top.first 0 "attr2" }}`))
+			})
+
+			It("should convert to field chain with root", func() {
+				formatter = &ppath.PathFormatterGoTemplate{
+					Root: ".Values",
+				}
+				path = ppath.NewPathFromSegments(dyaml.Segments{
+					dyaml.NewDocumentSegment(),
+					dyaml.NewKeySegment("top"),
+					dyaml.NewKeySegment("first"),
+				})
+
+				Expect(path.ToString(formatter)).To(Equal("{{ .Values.top.first }}"))
+			})
+
+			It("should give keys which are not identifier to index function", func() {
+				path = ppath.NewPathFromSegments(dyaml.Segments{
+					dyaml.NewDocumentSegment(),
+					dyaml.NewKeySegment("app.kubernetes.io/name"),
+					dyaml.NewKeySegment("first"),
+				})
+
+				Expect(path.ToString(formatter)).To(Equal(`{{ index . "app.kubernetes.io/name" "first" }}`))
+			})
+		})
+
+		Context("converting to kubectl format", func() {
+			BeforeEach(func() {
+				formatter = &ppath.PathFormatterKubectlExplain{}
+				data := []byte(`apiVersion: apps/v1
+kind: Deployment
+spec:
+  template:
+    spec:
+      containers:
+        - name: app
+          image: nginx
+`)
+				var err error
+				path, err = ppath.NewPath(bytes.NewReader(data), dmatcher.NewNodeMatcherByLineAndCol(8, 18))
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should convert to kubectl format with kind of the document", func() {
+				strpath, err := path.ToString(formatter)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(strpath).To(Equal("deployment.spec.template.spec.containers.image"))
+			})
+
+			It("should convert with given kind", func() {
+				formatter = &ppath.PathFormatterKubectlExplain{
+					Kind: "StatefulSet",
+				}
+
+				Expect(path.ToString(formatter)).To(Equal("statefulset.spec.template.spec.containers.image"))
+			})
+
+			It("should fail without kind", func() {
+				path = ppath.NewPathFromSegments(dyaml.Segments{
+					dyaml.NewDocumentSegment(),
+					dyaml.NewKeySegment("spec"),
+				})
+
+				_, err := path.ToString(formatter)
+
+				Expect(err).To(HaveOccurred())
+			})
 		})
 	})
 })
@@ -0,0 +1,291 @@
+package path
+
+import (
+	"bytes"
+	"fmt"
+
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// BoshOperation is an operation of BOSH ops-file.
+type BoshOperation struct {
+	Type  string       `yaml:"type"`
+	Path  string       `yaml:"path"`
+	Value *yamlv3.Node `yaml:"value,omitempty"`
+}
+
+// NewBoshOperation returns the operation of the type, "replace" or "remove",
+// for the node the path indicates. The value of "replace" is the current node.
+// The formatter is expected to format the path in bosh format, and the
+// document root, whose path is empty, cannot be operated.
+func NewBoshOperation(path *Path, formatter PathFormatter, opType string) (operation *BoshOperation, err error) {
+	strpath, err := path.ToString(formatter)
+	if err != nil {
+		return nil, fmt.Errorf("format path: %w", err)
+	}
+	if strpath == "" {
+		return nil, fmt.Errorf("unsupported operation on the document root")
+	}
+
+	operation = &BoshOperation{
+		Type: opType,
+		Path: strpath,
+	}
+	switch opType {
+	case "replace":
+		if operation.Value, err = pathValue(path); err != nil {
+			return nil, err
+		}
+	case "remove":
+	default:
+		return nil, fmt.Errorf("unsupported operation type: %s", opType)
+	}
+	return operation, nil
+}
+
+// NewBoshOperationFormatter returns the formatter of the path of BoshOperation.
+// The path of ops-file is always separated by "/", so only "bosh.name" is
+// taken from the options.
+func NewBoshOperationFormatter(options FormatterOptions) (formatter PathFormatter, err error) {
+	return NewFormatter(string(Bosh), FormatterOptions{
+		"bosh.sep":  "/",
+		"bosh.name": options.Get("bosh.name", "name"),
+	})
+}
+
+// Marshal returns the ops-file consisting of the operation.
+func (o *BoshOperation) Marshal() (data []byte, err error) {
+	return marshalYAML([]*BoshOperation{o})
+}
+
+// pathValue returns the copy of the node the path indicates, which can be
+// serialized alone.
+func pathValue(path *Path) (value *yamlv3.Node, err error) {
+	segments, err := path.Segments()
+	if err != nil {
+		return nil, fmt.Errorf("get segments: %w", err)
+	}
+	if len(segments) == 0 || segments[len(segments)-1].Node == nil {
+		return nil, fmt.Errorf("node not found: %s", path)
+	}
+
+	node := segments[len(segments)-1].Node
+	if node.Kind == yamlv3.DocumentNode {
+		if len(node.Content) == 0 {
+			return nil, fmt.Errorf("empty document: %s", path)
+		}
+		node = node.Content[0]
+	}
+	return detachNode(node), nil
+}
+
+// detachNode returns the deep copy of the node, whose aliases are replaced
+// with the nodes they refer, because the anchors may be outside of the node.
+func detachNode(node *yamlv3.Node) *yamlv3.Node {
+	if node.Kind == yamlv3.AliasNode && node.Alias != nil {
+		copied := detachNode(node.Alias)
+		copied.Anchor = ""
+		return copied
+	}
+
+	copied := *node
+	copied.Content = nil
+	for _, child := range node.Content {
+		copied.Content = append(copied.Content, detachNode(child))
+	}
+	return &copied
+}
+
+func marshalYAML(v any) (data []byte, err error) {
+	var buf bytes.Buffer
+	encoder := yamlv3.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(v); err != nil {
+		return nil, fmt.Errorf("encode yaml: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("encode yaml: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// JSONPatchOperation is an operation of JSON Patch defined in RFC 6902.
+type JSONPatchOperation struct {
+	Op    string       `yaml:"op"`
+	Path  string       `yaml:"path"`
+	Value *yamlv3.Node `yaml:"value,omitempty"`
+}
+
+// NewJSONPatchOperation returns the operation of the op, "replace", "remove",
+// "add" or "test", for the node the path indicates. The value of the
+// operations other than "remove" is the current node.
+func NewJSONPatchOperation(path *Path, op string) (operation *JSONPatchOperation, err error) {
+	strpath, err := path.ToString(&PathFormatterJSONPointer{})
+	if err != nil {
+		return nil, fmt.Errorf("format path: %w", err)
+	}
+
+	operation = &JSONPatchOperation{
+		Op:   op,
+		Path: strpath,
+	}
+	switch op {
+	case "replace", "add", "test":
+		if operation.Value, err = pathValue(path); err != nil {
+			return nil, err
+		}
+	case "remove":
+	default:
+		return nil, fmt.Errorf("unsupported operation: %s", op)
+	}
+	return operation, nil
+}
+
+// Marshal returns the JSON Patch document consisting of the operation in
+// YAML.
+func (o *JSONPatchOperation) Marshal() (data []byte, err error) {
+	return marshalYAML([]*JSONPatchOperation{o})
+}
+
+// MarshalJSON returns the operation in JSON, keeping the order of the mapping
+// keys in the value.
+func (o *JSONPatchOperation) MarshalJSON() (data []byte, err error) {
+	var buf bytes.Buffer
+	buf.WriteString(`{"op":` + jsonQuote(o.Op) + `,"path":` + jsonQuote(o.Path))
+	if o.Value != nil {
+		buf.WriteString(`,"value":`)
+		if err := writeJSONNode(&buf, o.Value); err != nil {
+			return nil, err
+		}
+	}
+	buf.WriteString("}")
+	return buf.Bytes(), nil
+}
+
+const (
+	intTag   = "!!int"
+	floatTag = "!!float"
+	boolTag  = "!!bool"
+	nullTag  = "!!null"
+	mergeTag = "!!merge"
+)
+
+// writeJSONNode writes the node in JSON. Mapping keys are written as strings,
+// and scalars are written as strings unless they are numbers, booleans or null.
+func writeJSONNode(buf *bytes.Buffer, node *yamlv3.Node) error {
+	switch node.Kind {
+	case yamlv3.AliasNode:
+		return writeJSONNode(buf, node.Alias)
+	case yamlv3.DocumentNode:
+		if len(node.Content) == 0 {
+			buf.WriteString("null")
+			return nil
+		}
+		return writeJSONNode(buf, node.Content[0])
+	case yamlv3.SequenceNode:
+		buf.WriteString("[")
+		for i, child := range node.Content {
+			if i > 0 {
+				buf.WriteString(",")
+			}
+			if err := writeJSONNode(buf, child); err != nil {
+				return err
+			}
+		}
+		buf.WriteString("]")
+	case yamlv3.MappingNode:
+		buf.WriteString("{")
+		pairs, err := mappingPairs(node)
+		if err != nil {
+			return err
+		}
+		for i, pair := range pairs {
+			if i > 0 {
+				buf.WriteString(",")
+			}
+			buf.WriteString(jsonQuote(pair[0].Value) + ":")
+			if err := writeJSONNode(buf, pair[1]); err != nil {
+				return err
+			}
+		}
+		buf.WriteString("}")
+	case yamlv3.ScalarNode:
+		switch node.ShortTag() {
+		case intTag, floatTag, boolTag, nullTag:
+			var value any
+			if err := node.Decode(&value); err != nil {
+				return fmt.Errorf("decode %q: %w", node.Value, err)
+			}
+			data, err := jsonEncode(value)
+			if err != nil {
+				return fmt.Errorf("encode %q: %w", node.Value, err)
+			}
+			buf.Write(data)
+		default:
+			// timestamps and custom tags are kept as written in the source
+			buf.WriteString(jsonQuote(node.Value))
+		}
+	default:
+		return fmt.Errorf("invalid node kind: %d", node.Kind)
+	}
+	return nil
+}
+
+// mappingPairs returns the key and value pairs of the mapping, with merge keys
+// expanded. As yaml.v3 decodes, keys in the mapping override merged ones, and
+// earlier mappings in a merged sequence override later ones.
+func mappingPairs(node *yamlv3.Node) (pairs [][2]*yamlv3.Node, err error) {
+	explicit := map[string]bool{}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if key := node.Content[i]; key.ShortTag() != mergeTag {
+			explicit[key.Value] = true
+		}
+	}
+
+	seen := map[string]bool{}
+	add := func(key, value *yamlv3.Node) {
+		if seen[key.Value] {
+			return
+		}
+		seen[key.Value] = true
+		pairs = append(pairs, [2]*yamlv3.Node{key, value})
+	}
+	merge := func(value *yamlv3.Node) error {
+		if value.Kind == yamlv3.AliasNode {
+			value = value.Alias
+		}
+		if value.Kind != yamlv3.MappingNode {
+			return fmt.Errorf("merge non-mapping value at line %d", value.Line)
+		}
+		merged, err := mappingPairs(value)
+		if err != nil {
+			return err
+		}
+		for _, pair := range merged {
+			if !explicit[pair[0].Value] {
+				add(pair[0], pair[1])
+			}
+		}
+		return nil
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if key.ShortTag() != mergeTag {
+			add(key, value)
+			continue
+		}
+		if value.Kind == yamlv3.SequenceNode {
+			for _, item := range value.Content {
+				if err := merge(item); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+		if err := merge(value); err != nil {
+			return nil, err
+		}
+	}
+	return pairs, nil
+}
@@ -0,0 +1,151 @@
+package path_test
+
+import (
+	"bytes"
+	"encoding/json"
+
+	dmatcher "github.com/gidoichi/yaml-path/domain/matcher"
+	ppath "github.com/gidoichi/yaml-path/presentation/path"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Operation", func() {
+	data := []byte(`base: &base
+  image: nginx
+top:
+  first:
+    - name: myname
+      attr2: val2
+    - id: other
+      base: *base
+`)
+	newPath := func(line, col int) *ppath.Path {
+		path, err := ppath.NewEnclosingPath(bytes.NewReader(data), dmatcher.NewNodeMatcherByLineAndCol(line, col))
+		Expect(err).NotTo(HaveOccurred())
+		return path
+	}
+	bosh := &ppath.PathFormatterBosh{
+		Separator: "/",
+		NameAttr:  "name",
+	}
+
+	Describe("NewBoshOperation()", func() {
+		It("should create replace operation with the current value", func() {
+			operation, err := ppath.NewBoshOperation(newPath(5, 5), bosh, "replace")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(operation.Marshal()).To(Equal([]byte(`- type: replace
+  path: /top/first/name=myname
+  value:
+    name: myname
+    attr2: val2
+`)))
+		})
+
+		It("should create remove operation without value", func() {
+			operation, err := ppath.NewBoshOperation(newPath(6, 7), bosh, "remove")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(operation.Marshal()).To(Equal([]byte(`- type: remove
+  path: /top/first/name=myname/attr2
+`)))
+		})
+
+		It("should resolve aliases in the value", func() {
+			operation, err := ppath.NewBoshOperation(newPath(8, 7), bosh, "replace")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(operation.Marshal()).To(Equal([]byte(`- type: replace
+  path: /top/first/1/base
+  value:
+    image: nginx
+`)))
+		})
+
+		It("should fail with unsupported type", func() {
+			_, err := ppath.NewBoshOperation(newPath(6, 7), bosh, "move")
+
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should fail at the document root", func() {
+			path, err := ppath.NewEnclosingPath(bytes.NewReader([]byte("scalar\n")), dmatcher.NewNodeMatcherByLine(1))
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = ppath.NewBoshOperation(path, bosh, "replace")
+
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("NewBoshOperationFormatter()", func() {
+		It("should separate the path by slash with the name attribute", func() {
+			formatter, err := ppath.NewBoshOperationFormatter(ppath.FormatterOptions{
+				"bosh.sep":  ".",
+				"bosh.name": "id",
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			operation, err := ppath.NewBoshOperation(newPath(8, 7), formatter, "remove")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(operation.Path).To(Equal("/top/first/id=other/base"))
+		})
+	})
+
+	Describe("NewJSONPatchOperation()", func() {
+		It("should create replace operation with the current value", func() {
+			operation, err := ppath.NewJSONPatchOperation(newPath(5, 5), "replace")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(operation.Marshal()).To(Equal([]byte(`- op: replace
+  path: /top/first/0
+  value:
+    name: myname
+    attr2: val2
+`)))
+			Expect(json.Marshal(operation)).To(Equal([]byte(`{"op":"replace","path":"/top/first/0","value":{"name":"myname","attr2":"val2"}}`)))
+		})
+
+		It("should create remove operation without value", func() {
+			operation, err := ppath.NewJSONPatchOperation(newPath(6, 7), "remove")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(json.Marshal(operation)).To(Equal([]byte(`{"op":"remove","path":"/top/first/0/attr2"}`)))
+		})
+
+		It("should resolve aliases in the value", func() {
+			operation, err := ppath.NewJSONPatchOperation(newPath(8, 7), "test")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(json.Marshal(operation)).To(Equal([]byte(`{"op":"test","path":"/top/first/1/base","value":{"image":"nginx"}}`)))
+		})
+
+		It("should keep the value as written in the source", func() {
+			data := []byte(`base: &base
+  x: 1
+  y: 2
+top:
+  <<: *base
+  y: 3
+  date: 2001-12-14
+  html: "a<b&c>"
+  hex: 0x10
+  none: ~
+`)
+			path, err := ppath.NewEnclosingPath(bytes.NewReader(data), dmatcher.NewNodeMatcherByLine(4))
+			Expect(err).NotTo(HaveOccurred())
+			operation, err := ppath.NewJSONPatchOperation(path, "test")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(operation.MarshalJSON()).To(Equal([]byte(`{"op":"test","path":"/top","value":{"x":1,"y":3,"date":"2001-12-14","html":"a<b&c>","hex":16,"none":null}}`)))
+		})
+
+		It("should fail with unsupported operation", func() {
+			_, err := ppath.NewJSONPatchOperation(newPath(6, 7), "copy")
+
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,280 @@
+package path
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	dyaml "github.com/gidoichi/yaml-path/domain/yaml"
+)
+
+type PathParser interface {
+	Parse(strpath string) (path *Path, err error)
+}
+
+type PathParserBosh struct {
+	Separator string
+	NameAttr  string
+}
+
+func (p *PathParserBosh) Parse(strpath string) (path *Path, err error) {
+	segments := dyaml.Segments{dyaml.NewDocumentSegment()}
+	if strpath == "" {
+		return NewPathFromSegments(segments), nil
+	}
+	if p.Separator == "" {
+		return nil, fmt.Errorf("empty separator")
+	}
+	rest, found := strings.CutPrefix(strpath, p.Separator)
+	if !found {
+		return nil, fmt.Errorf("path must start with %q: %s", p.Separator, strpath)
+	}
+	if rest == "" {
+		return NewPathFromSegments(segments), nil
+	}
+
+	for _, token := range strings.Split(rest, p.Separator) {
+		if idx, err := strconv.Atoi(token); err == nil && idx >= 0 {
+			segments = append(segments, dyaml.NewIndexSegment(idx))
+			continue
+		}
+		if p.NameAttr != "" {
+			if value, found := strings.CutPrefix(token, p.NameAttr+"="); found && value != "" {
+				segments = append(segments, dyaml.NewSelectorSegment(p.NameAttr, value))
+				continue
+			}
+		}
+		segments = append(segments, dyaml.NewKeySegment(token))
+	}
+
+	return NewPathFromSegments(segments), nil
+}
+
+// PathParserJSONPath parses JSONPath defined in RFC 9535, limited to the
+// paths selecting a single node: member names, indices and filter selectors
+// comparing a member with a string, e.g. `[?@.name=='myname']`.
+type PathParserJSONPath struct{}
+
+func (p *PathParserJSONPath) Parse(strpath string) (path *Path, err error) {
+	rest, found := strings.CutPrefix(strpath, "$")
+	if !found {
+		return nil, fmt.Errorf("path must start with %q: %s", "$", strpath)
+	}
+
+	segments := dyaml.Segments{dyaml.NewDocumentSegment()}
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			key := rest[1 : end+1]
+			if key == "" {
+				return nil, fmt.Errorf("empty key: %s", strpath)
+			}
+			segments = append(segments, dyaml.NewKeySegment(key))
+			rest = rest[end+1:]
+		case '[':
+			segment, n, err := parseJSONPathSelector(rest[1:])
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s", err, strpath)
+			}
+			rest = rest[1+n:]
+			if !strings.HasPrefix(rest, "]") {
+				return nil, fmt.Errorf("unclosed bracket: %s", strpath)
+			}
+			segments = append(segments, segment)
+			rest = rest[1:]
+		default:
+			return nil, fmt.Errorf("unexpected character %q: %s", rest[0], strpath)
+		}
+	}
+
+	return NewPathFromSegments(segments), nil
+}
+
+// parseJSONPathSelector parses the selector in brackets, and returns the
+// segment and the length of the selector.
+func parseJSONPathSelector(s string) (segment dyaml.Segment, n int, err error) {
+	if s == "" {
+		return dyaml.Segment{}, 0, fmt.Errorf("unclosed bracket")
+	}
+	switch s[0] {
+	case '\'', '"':
+		key, n, err := parseJSONPathString(s)
+		if err != nil {
+			return dyaml.Segment{}, 0, err
+		}
+		return dyaml.NewKeySegment(key), n, nil
+	case '?':
+		rest, found := strings.CutPrefix(s[1:], "@")
+		if !found {
+			return dyaml.Segment{}, 0, fmt.Errorf("unsupported filter: %s", s)
+		}
+		var key string
+		switch {
+		case strings.HasPrefix(rest, "."):
+			end := strings.IndexAny(rest[1:], "=]")
+			if end < 0 {
+				return dyaml.Segment{}, 0, fmt.Errorf("unsupported filter: %s", s)
+			}
+			key = rest[1 : end+1]
+			rest = rest[end+1:]
+		case strings.HasPrefix(rest, "["):
+			k, n, err := parseJSONPathString(rest[1:])
+			if err != nil {
+				return dyaml.Segment{}, 0, err
+			}
+			if !strings.HasPrefix(rest[1+n:], "]") {
+				return dyaml.Segment{}, 0, fmt.Errorf("unclosed bracket")
+			}
+			key = k
+			rest = rest[1+n+1:]
+		default:
+			return dyaml.Segment{}, 0, fmt.Errorf("unsupported filter: %s", s)
+		}
+		rest, found = strings.CutPrefix(rest, "==")
+		if !found || key == "" {
+			return dyaml.Segment{}, 0, fmt.Errorf("unsupported filter: %s", s)
+		}
+		value, n, err := parseJSONPathString(rest)
+		if err != nil {
+			return dyaml.Segment{}, 0, err
+		}
+		rest = rest[n:]
+		return dyaml.NewSelectorSegment(key, value), len(s) - len(rest), nil
+	default:
+		end := strings.IndexByte(s, ']')
+		if end < 0 {
+			return dyaml.Segment{}, 0, fmt.Errorf("unclosed bracket")
+		}
+		if idx, err := strconv.Atoi(s[:end]); err == nil && idx >= 0 {
+			return dyaml.NewIndexSegment(idx), end, nil
+		}
+		return dyaml.Segment{}, 0, fmt.Errorf("unsupported selector %q", s[:end])
+	}
+}
+
+// parseJSONPathString parses the string literal at the beginning of s, and
+// returns the value and the length of the literal.
+func parseJSONPathString(s string) (value string, n int, err error) {
+	if s == "" || (s[0] != '\'' && s[0] != '"') {
+		return "", 0, fmt.Errorf("string literal expected")
+	}
+	quote := s[0]
+	var builder strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; c {
+		case quote:
+			return builder.String(), i + 1, nil
+		case '\\':
+			i++
+			if i == len(s) {
+				return "", 0, fmt.Errorf("unterminated string")
+			}
+			switch e := s[i]; e {
+			case 'b':
+				builder.WriteByte('\b')
+			case 'f':
+				builder.WriteByte('\f')
+			case 'n':
+				builder.WriteByte('\n')
+			case 'r':
+				builder.WriteByte('\r')
+			case 't':
+				builder.WriteByte('\t')
+			case '/', '\\', '\'', '"':
+				builder.WriteByte(e)
+			case 'u':
+				r, m, err := parseJSONPathUnicode(s[i+1:])
+				if err != nil {
+					return "", 0, err
+				}
+				builder.WriteRune(r)
+				i += m
+			default:
+				return "", 0, fmt.Errorf("invalid escape %q", e)
+			}
+		default:
+			builder.WriteByte(c)
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated string")
+}
+
+// parseJSONPathUnicode parses hex digits of \u escape, with the low surrogate
+// following if any, and returns the rune and the length of the escape.
+func parseJSONPathUnicode(s string) (r rune, n int, err error) {
+	if len(s) < 4 {
+		return 0, 0, fmt.Errorf("invalid unicode escape")
+	}
+	hi, err := strconv.ParseUint(s[:4], 16, 16)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid unicode escape: %w", err)
+	}
+	if !utf16.IsSurrogate(rune(hi)) {
+		return rune(hi), 4, nil
+	}
+	if len(s) < 10 || s[4:6] != `\u` {
+		return 0, 0, fmt.Errorf("invalid surrogate pair")
+	}
+	lo, err := strconv.ParseUint(s[6:10], 16, 16)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid unicode escape: %w", err)
+	}
+	r = utf16.DecodeRune(rune(hi), rune(lo))
+	if r == utf8.RuneError {
+		return 0, 0, fmt.Errorf("invalid surrogate pair")
+	}
+	return r, 10, nil
+}
+
+// PathParserJSONPointer parses JSON Pointer defined in RFC 6901. Reference
+// tokens of array index form are parsed into index segments, which also select
+// the mapping entry whose key is the number.
+type PathParserJSONPointer struct{}
+
+var jsonPointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+
+func (p *PathParserJSONPointer) Parse(strpath string) (path *Path, err error) {
+	segments := dyaml.Segments{dyaml.NewDocumentSegment()}
+	if strpath == "" {
+		return NewPathFromSegments(segments), nil
+	}
+	rest, found := strings.CutPrefix(strpath, "/")
+	if !found {
+		return nil, fmt.Errorf("path must start with %q: %s", "/", strpath)
+	}
+
+	for _, token := range strings.Split(rest, "/") {
+		if !validJSONPointerToken(token) {
+			return nil, fmt.Errorf("invalid escape %q: %s", token, strpath)
+		}
+		// leading zeros and signs are not array index
+		if idx, err := strconv.Atoi(token); err == nil && idx >= 0 && strconv.Itoa(idx) == token {
+			segments = append(segments, dyaml.NewIndexSegment(idx))
+			continue
+		}
+		segments = append(segments, dyaml.NewKeySegment(jsonPointerUnescaper.Replace(token)))
+	}
+
+	return NewPathFromSegments(segments), nil
+}
+
+// validJSONPointerToken reports whether every "~" in the token is followed by
+// "0" or "1".
+func validJSONPointerToken(token string) bool {
+	for i := 0; i < len(token); i++ {
+		if token[i] != '~' {
+			continue
+		}
+		if i+1 == len(token) || (token[i+1] != '0' && token[i+1] != '1') {
+			return false
+		}
+		i++
+	}
+	return true
+}
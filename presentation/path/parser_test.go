@@ -0,0 +1,181 @@
+package path_test
+
+import (
+	dyaml "github.com/gidoichi/yaml-path/domain/yaml"
+	ppath "github.com/gidoichi/yaml-path/presentation/path"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PathParser", func() {
+	var (
+		parser    ppath.PathParser
+		formatter ppath.PathFormatter
+	)
+
+	Describe("Parse()", func() {
+		Context("parsing bosh format", func() {
+			BeforeEach(func() {
+				parser = &ppath.PathParserBosh{
+					Separator: "/",
+					NameAttr:  "name",
+				}
+				formatter = &ppath.PathFormatterBosh{
+					Separator: "/",
+					NameAttr:  "name",
+				}
+			})
+
+			DescribeTable("should round-trip through the formatter",
+				func(strpath string) {
+					path, err := parser.Parse(strpath)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(path.ToString(formatter)).To(Equal(strpath))
+				},
+				Entry("with selector", "/top/first/name=myname/attr2"),
+				Entry("with index", "/top/first/1"),
+				Entry("with nested sequence", "/top/0/2/name=myname/name"),
+				Entry("with root", ""),
+			)
+
+			It("should parse into segments", func() {
+				path, err := parser.Parse("/top/first/name=myname/0")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(path.Segments()).To(Equal(dyaml.Segments{
+					dyaml.NewDocumentSegment(),
+					dyaml.NewKeySegment("top"),
+					dyaml.NewKeySegment("first"),
+					dyaml.NewSelectorSegment("name", "myname"),
+					dyaml.NewIndexSegment(0),
+				}))
+			})
+
+			It("should fail without leading separator", func() {
+				_, err := parser.Parse("top/first")
+
+				Expect(err).To(HaveOccurred())
+			})
+		})
+
+		Context("parsing bosh format with custom separator", func() {
+			BeforeEach(func() {
+				parser = &ppath.PathParserBosh{
+					Separator: ".",
+					NameAttr:  "id",
+				}
+				formatter = &ppath.PathFormatterBosh{
+					Separator: ".",
+					NameAttr:  "id",
+				}
+			})
+
+			It("should round-trip through the formatter", func() {
+				path, err := parser.Parse(".top.first.id=3.attr2")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(path.ToString(formatter)).To(Equal(".top.first.id=3.attr2"))
+			})
+		})
+
+		Context("parsing jsonpath format", func() {
+			BeforeEach(func() {
+				parser = &ppath.PathParserJSONPath{}
+				formatter = &ppath.PathFormatterJSONPath{
+					NameAttr: "name",
+				}
+			})
+
+			DescribeTable("should round-trip through the formatter",
+				func(strpath string) {
+					path, err := parser.Parse(strpath)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(path.ToString(formatter)).To(Equal(strpath))
+				},
+				Entry("with index", "$.top.first[0].attr2"),
+				Entry("with nested sequence", "$.top[0][2].name"),
+				Entry("with root", "$"),
+				Entry("with bracket notation", `$.metadata['app.kubernetes.io/name']['a]b']['it\'s\n']`),
+				Entry("with filter selector", "$.top.first[?@.name=='myname'].attr2"),
+			)
+
+			It("should parse filter selector into selector segment", func() {
+				path, err := parser.Parse(`$.top[?@.name=="my\u0041"][?@['the name']=='my]name']`)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(path.Segments()).To(Equal(dyaml.Segments{
+					dyaml.NewDocumentSegment(),
+					dyaml.NewKeySegment("top"),
+					dyaml.NewSelectorSegment("name", "myA"),
+					dyaml.NewSelectorSegment("the name", "my]name"),
+				}))
+			})
+
+			It("should parse bracketed keys", func() {
+				path, err := parser.Parse(`$['top']["first"][0]`)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(path.ToString(formatter)).To(Equal("$.top.first[0]"))
+			})
+
+			It("should fail with unclosed bracket", func() {
+				_, err := parser.Parse("$.top.first[0")
+
+				Expect(err).To(HaveOccurred())
+			})
+
+			It("should fail with wildcard", func() {
+				_, err := parser.Parse("$.top.first[*]")
+
+				Expect(err).To(HaveOccurred())
+			})
+
+			It("should fail with unterminated string", func() {
+				_, err := parser.Parse("$.top['first]")
+
+				Expect(err).To(HaveOccurred())
+			})
+		})
+
+		Context("parsing jsonpointer format", func() {
+			BeforeEach(func() {
+				parser = &ppath.PathParserJSONPointer{}
+				formatter = &ppath.PathFormatterJSONPointer{}
+			})
+
+			DescribeTable("should round-trip through the formatter",
+				func(strpath string) {
+					path, err := parser.Parse(strpath)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(path.ToString(formatter)).To(Equal(strpath))
+				},
+				Entry("with index", "/top/first/0/attr2"),
+				Entry("with escaped keys", "/app.kubernetes.io~1name/a~0b~01"),
+				Entry("with empty key", "/top//attr"),
+				Entry("with root", ""),
+			)
+
+			It("should parse into segments", func() {
+				path, err := parser.Parse("/top/01/a~1b/1")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(path.Segments()).To(Equal(dyaml.Segments{
+					dyaml.NewDocumentSegment(),
+					dyaml.NewKeySegment("top"),
+					dyaml.NewKeySegment("01"),
+					dyaml.NewKeySegment("a/b"),
+					dyaml.NewIndexSegment(1),
+				}))
+			})
+
+			It("should fail with invalid escape", func() {
+				_, err := parser.Parse("/top/a~2b")
+
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+})
@@ -7,18 +7,33 @@ import (
 	"strings"
 
 	dmatcher "github.com/gidoichi/yaml-path/domain/matcher"
+	"github.com/gidoichi/yaml-path/domain/source"
 	dyaml "github.com/gidoichi/yaml-path/domain/yaml"
 )
 
 type Format string
 
 const (
-	Bosh     Format = "bosh"
-	JsonPath Format = "jsonpath"
+	Bosh        Format = "bosh"
+	JsonPath    Format = "jsonpath"
+	JsonPointer Format = "jsonpointer"
+	Yq          Format = "yq"
+	Kustomize   Format = "kustomize"
+	Helm        Format = "helm"
+	Properties  Format = "properties"
+	Env         Format = "env"
+	Terraform   Format = "terraform"
+	Jq          Format = "jq"
+	Python      Format = "python"
+	JavaScript  Format = "javascript"
+	GoTemplate  Format = "gotemplate"
+	Template    Format = "template"
+	Kubectl     Format = "kubectl"
 )
 
 type Path struct {
 	dyaml.Path
+	segments dyaml.Segments
 }
 
 func (p *Path) Len() int {
@@ -29,26 +44,92 @@ func (p *Path) Get(i int) (node *dyaml.Node, err error) {
 	return p.Path.Get(i)
 }
 
+// Segments returns segments of the path. When the path is built from nodes
+// only, the segments are converted from them.
+func (p *Path) Segments() (segments dyaml.Segments, err error) {
+	if p.segments != nil {
+		return p.segments, nil
+	}
+	return p.Path.Segments()
+}
+
 func NewPath(in io.Reader, matcher dmatcher.NodeMatcher) (path *Path, err error) {
-	yaml, err := dyaml.NewYAML(in)
+	return newPath(in, matcher, (*dyaml.Stream).PathAtPoint)
+}
+
+// NewEnclosingPath returns the path to the token, or to the nearest node
+// enclosing the point if no token matches.
+func NewEnclosingPath(in io.Reader, matcher dmatcher.NodeMatcher) (path *Path, err error) {
+	return newPath(in, matcher, (*dyaml.Stream).PathEnclosingPoint)
+}
+
+func newPath(in io.Reader, matcher dmatcher.NodeMatcher, resolve func(*dyaml.Stream, dmatcher.NodeMatcher) (dyaml.Path, error)) (path *Path, err error) {
+	yaml, err := dyaml.NewStream(in)
 	if err != nil {
 		return nil, err
 	}
 
-	p, err := yaml.PathAtPoint(matcher)
+	p, err := resolve(yaml, matcher)
+	if err != nil {
+		return nil, err
+	}
+	segments, err := yaml.Segments(p)
 	if err != nil {
 		return nil, err
 	}
 
 	return &Path{
-		Path: p,
+		Path:     p,
+		segments: segments,
 	}, nil
 }
 
+func NewPathFromSegments(segments dyaml.Segments) (path *Path) {
+	return &Path{
+		segments: segments,
+	}
+}
+
+func NewLocation(in io.Reader, strpath string, parser PathParser) (location *dyaml.Location, err error) {
+	return NewLocationIn(in, strpath, parser, dmatcher.DefaultColumn)
+}
+
+// NewLocationIn returns the location whose column is counted in the way of
+// column.
+func NewLocationIn(in io.Reader, strpath string, parser PathParser, column dmatcher.Column) (location *dyaml.Location, err error) {
+	path, err := parser.Parse(strpath)
+	if err != nil {
+		return nil, err
+	}
+	segments, err := path.Segments()
+	if err != nil {
+		return nil, err
+	}
+
+	yaml, err := dyaml.NewStream(in)
+	if err != nil {
+		return nil, err
+	}
+
+	l, err := yaml.Locate(segments)
+	if err != nil {
+		return nil, err
+	}
+	l.Column = convertPosition(yaml.Source(), source.Position{Line: l.Line, Column: l.Column}, column).Column
+
+	return &l, nil
+}
+
 func (p *Path) String() (strpath string) {
 	var arr []string
-	for _, node := range p.Path {
-		arr = append(arr, fmt.Sprintf("%+v", node))
+	if p.segments != nil {
+		for _, segment := range p.segments {
+			arr = append(arr, fmt.Sprintf("%s(%s)", segment.Kind, segment))
+		}
+	} else {
+		for _, node := range p.Path {
+			arr = append(arr, fmt.Sprintf("%+v", node))
+		}
 	}
 	str := strings.Join(arr, ",")
 	return fmt.Sprintf("%s[%s]", reflect.TypeOf(p), str)
@@ -0,0 +1,30 @@
+package path_test
+
+import (
+	"bytes"
+
+	dmatcher "github.com/gidoichi/yaml-path/domain/matcher"
+	dyaml "github.com/gidoichi/yaml-path/domain/yaml"
+	ppath "github.com/gidoichi/yaml-path/presentation/path"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Path", func() {
+	data := []byte("キー: {a: 1, b: 2}\n")
+	parser := &ppath.PathParserBosh{Separator: "/", NameAttr: "name"}
+
+	Describe("NewLocationIn()", func() {
+		DescribeTable("should return the column counted in the given unit",
+			func(column dmatcher.Column, expected int) {
+				location, err := ppath.NewLocationIn(bytes.NewReader(data), "/キー/b", parser, column)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(location).To(Equal(&dyaml.Location{Document: 0, Line: 1, Column: expected}))
+			},
+			Entry("in 1-based runes", dmatcher.DefaultColumn, 12),
+			Entry("in 1-based bytes", dmatcher.Column{Unit: dmatcher.ColumnByte, Base: 1}, 16),
+			Entry("in 0-based visual columns", dmatcher.Column{Unit: dmatcher.ColumnVisual, Base: 0, TabWidth: 8}, 13),
+		)
+	})
+})
@@ -0,0 +1,99 @@
+package path
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	dyaml "github.com/gidoichi/yaml-path/domain/yaml"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// PathFormatterPlugin formats the path by the external executable. The
+// executable receives PluginInput as JSON on stdin, and writes the formatted
+// path on stdout. A trailing newline of the output is removed.
+type PathFormatterPlugin struct {
+	Command string
+	Options FormatterOptions
+}
+
+// PluginInput is the structured path given to the external formatter.
+type PluginInput struct {
+	Segments []PluginSegment  `json:"segments"`
+	Options  FormatterOptions `json:"options"`
+}
+
+// PluginSegment is a segment of the path given to the external formatter.
+type PluginSegment struct {
+	// Kind is "document", "key", "index" or "selector".
+	Kind string `json:"kind"`
+	// Key is the mapping key for "key", or the attribute for "selector".
+	Key string `json:"key,omitempty"`
+	// Value is the value of the attribute for "selector".
+	Value string `json:"value,omitempty"`
+	// Index is the index of the document for "document", or the sequence
+	// item for "index" and "selector". -1 if unknown.
+	Index int `json:"index"`
+	// Selections are the values of the scalar attributes which select the
+	// sequence item uniquely, for "index".
+	Selections map[string]string `json:"selections,omitempty"`
+	// Line and Column are the position in the source, zero if unknown.
+	Line   int `json:"line,omitempty"`
+	Column int `json:"column,omitempty"`
+}
+
+func (f *PathFormatterPlugin) ToString(path *Path) (strpath string, err error) {
+	segments, err := path.Segments()
+	if err != nil {
+		return "", fmt.Errorf("get segments: %w", err)
+	}
+
+	input := PluginInput{
+		Segments: []PluginSegment{},
+		Options:  f.Options,
+	}
+	for _, segment := range segments {
+		input.Segments = append(input.Segments, newPluginSegment(segment))
+	}
+	data, err := json.Marshal(input)
+	if err != nil {
+		return "", fmt.Errorf("encode path: %w", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(f.Command)
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("run %s: %w: %s", f.Command, err, strings.TrimSpace(stderr.String()))
+	}
+
+	return strings.TrimSuffix(strings.TrimSuffix(stdout.String(), "\n"), "\r"), nil
+}
+
+func newPluginSegment(segment dyaml.Segment) PluginSegment {
+	s := PluginSegment{
+		Kind:   segment.Kind.String(),
+		Key:    segment.Key,
+		Value:  segment.Value,
+		Index:  segment.Index,
+		Line:   segment.Line,
+		Column: segment.Column,
+	}
+	if segment.Kind != dyaml.IndexSegment || segment.Node == nil || segment.Node.Kind != yamlv3.MappingNode {
+		return s
+	}
+	for i := 0; i+1 < len(segment.Node.Content); i += 2 {
+		key := segment.Node.Content[i].Value
+		if value := segment.Selection(key); value != "" {
+			if s.Selections == nil {
+				s.Selections = map[string]string{}
+			}
+			s.Selections[key] = value
+		}
+	}
+	return s
+}
@@ -0,0 +1,177 @@
+package path
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// FormatterOptions are the options given to FormatterFactory, keyed by
+// "<format>.<option>", e.g. "bosh.sep".
+type FormatterOptions map[string]string
+
+// Get returns the value of the option, or def if the option is not given.
+func (o FormatterOptions) Get(key, def string) string {
+	if value, ok := o[key]; ok {
+		return value
+	}
+	return def
+}
+
+// FormatterFactory creates the formatter with the options.
+type FormatterFactory func(options FormatterOptions) (PathFormatter, error)
+
+// PluginPrefix is the prefix of the executable name of the external formatter,
+// see PathFormatterPlugin.
+const PluginPrefix = "yaml-path-format-"
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]FormatterFactory{}
+)
+
+// RegisterFormatter makes the formatter available by the name. If it is
+// called twice with the same name, it panics.
+func RegisterFormatter(name string, factory FormatterFactory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if factory == nil {
+		panic("path: RegisterFormatter factory is nil")
+	}
+	if _, dup := registry[name]; dup {
+		panic("path: RegisterFormatter called twice for formatter " + name)
+	}
+	registry[name] = factory
+}
+
+// Formatters returns the sorted names of the registered formatters.
+func Formatters() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	var names []string
+	for name := range registry {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// NewFormatter creates the formatter registered by the name. If no formatter
+// is registered, the external executable named PluginPrefix + name found in
+// PATH is used. The name of the executable must not contain path separators.
+func NewFormatter(name string, options FormatterOptions) (formatter PathFormatter, err error) {
+	registryMu.RLock()
+	factory, ok := registry[name]
+	registryMu.RUnlock()
+	if ok {
+		return factory(options)
+	}
+
+	// a name with separator would be looked up as a path instead of in PATH
+	if strings.ContainsAny(name, "/"+string(os.PathSeparator)) {
+		return nil, fmt.Errorf("unsupported path format: %s", name)
+	}
+	command, err := exec.LookPath(PluginPrefix + name)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported path format: %s", name)
+	}
+	return &PathFormatterPlugin{
+		Command: command,
+		Options: options,
+	}, nil
+}
+
+func init() {
+	RegisterFormatter(string(Bosh), func(options FormatterOptions) (PathFormatter, error) {
+		f := &PathFormatterBosh{
+			Separator: "/",
+			NameAttr:  "name",
+		}
+		if sep := options.Get("bosh.sep", ""); sep != "" {
+			f.Separator = sep
+		}
+		if attr, ok := options["bosh.name"]; ok {
+			f.NameAttr = attr
+		}
+		return f, nil
+	})
+	RegisterFormatter(string(JsonPath), func(options FormatterOptions) (PathFormatter, error) {
+		return &PathFormatterJSONPath{
+			NameAttr: options.Get("jsonpath.name", ""),
+		}, nil
+	})
+	RegisterFormatter(string(JsonPointer), func(options FormatterOptions) (PathFormatter, error) {
+		return &PathFormatterJSONPointer{}, nil
+	})
+	RegisterFormatter(string(Yq), func(options FormatterOptions) (PathFormatter, error) {
+		return &PathFormatterYq{
+			NameAttr: options.Get("yq.name", ""),
+		}, nil
+	})
+	RegisterFormatter(string(Kustomize), func(options FormatterOptions) (PathFormatter, error) {
+		return &PathFormatterKustomize{
+			NameAttr: options.Get("kustomize.name", "name"),
+		}, nil
+	})
+	RegisterFormatter(string(Helm), func(options FormatterOptions) (PathFormatter, error) {
+		return &PathFormatterHelm{}, nil
+	})
+	RegisterFormatter(string(Properties), func(options FormatterOptions) (PathFormatter, error) {
+		return &PathFormatterProperties{
+			Separator:  options.Get("properties.sep", "."),
+			IndexStyle: IndexStyle(options.Get("properties.index", string(IndexStyleBracket))),
+		}, nil
+	})
+	RegisterFormatter(string(Env), func(options FormatterOptions) (PathFormatter, error) {
+		return &PathFormatterEnv{
+			Prefix:     options.Get("env.prefix", ""),
+			Separator:  options.Get("env.sep", "_"),
+			Case:       Case(options.Get("env.case", string(CaseUpper))),
+			IndexStyle: IndexStyle(options.Get("env.index", string(IndexStyleSeparator))),
+		}, nil
+	})
+	RegisterFormatter(string(Terraform), func(options FormatterOptions) (PathFormatter, error) {
+		return &PathFormatterTerraform{
+			Root: options.Get("terraform.root", "local.cfg"),
+		}, nil
+	})
+	RegisterFormatter(string(Jq), func(options FormatterOptions) (PathFormatter, error) {
+		return &PathFormatterJq{
+			NameAttr: options.Get("jq.name", ""),
+		}, nil
+	})
+	subscript := func(options FormatterOptions) (PathFormatter, error) {
+		return &PathFormatterSubscript{
+			Root: options.Get("subscript.root", "data"),
+		}, nil
+	}
+	RegisterFormatter(string(Python), subscript)
+	RegisterFormatter(string(JavaScript), subscript)
+	RegisterFormatter(string(GoTemplate), func(options FormatterOptions) (PathFormatter, error) {
+		return &PathFormatterGoTemplate{
+			Root: options.Get("gotemplate.root", ""),
+		}, nil
+	})
+	RegisterFormatter(string(Template), func(options FormatterOptions) (PathFormatter, error) {
+		text := options.Get("template.text", "")
+		if file := options.Get("template.file", ""); file != "" {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return nil, fmt.Errorf("read template: %w", err)
+			}
+			text = string(data)
+		}
+		if text == "" {
+			return nil, fmt.Errorf(`option "template.text" or "template.file" is required for template format`)
+		}
+		return NewPathFormatterTemplate(text, options.Get("template.name", "name"))
+	})
+	RegisterFormatter(string(Kubectl), func(options FormatterOptions) (PathFormatter, error) {
+		return &PathFormatterKubectlExplain{
+			Kind: options.Get("kubectl.kind", ""),
+		}, nil
+	})
+}
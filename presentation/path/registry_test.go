@@ -0,0 +1,120 @@
+package path_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	dmatcher "github.com/gidoichi/yaml-path/domain/matcher"
+	ppath "github.com/gidoichi/yaml-path/presentation/path"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type upperFormatter struct{}
+
+func (f *upperFormatter) ToString(path *ppath.Path) (string, error) {
+	return "UPPER", nil
+}
+
+var _ = Describe("Formatter registry", func() {
+	var path *ppath.Path
+
+	BeforeEach(func() {
+		data := []byte(`top:
+  first:
+    - name: myname
+      attr1: val1
+      attr2: val2
+`)
+		var err error
+		path, err = ppath.NewPath(bytes.NewReader(data), dmatcher.NewNodeMatcherByLineAndCol(5, 14))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	Describe("NewFormatter()", func() {
+		It("should create built-in formatter with options", func() {
+			formatter, err := ppath.NewFormatter("bosh", ppath.FormatterOptions{"bosh.sep": "."})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(path.ToString(formatter)).To(Equal(".top.first.name=myname.attr2"))
+		})
+
+		It("should create built-in formatter with default options", func() {
+			formatter, err := ppath.NewFormatter("properties", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(path.ToString(formatter)).To(Equal("top.first[0].attr2"))
+		})
+
+		It("should create registered formatter", func() {
+			ppath.RegisterFormatter("test-upper", func(options ppath.FormatterOptions) (ppath.PathFormatter, error) {
+				return &upperFormatter{}, nil
+			})
+			DeferCleanup(ppath.UnregisterFormatter, "test-upper")
+
+			Expect(ppath.Formatters()).To(ContainElement("test-upper"))
+			formatter, err := ppath.NewFormatter("test-upper", nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(path.ToString(formatter)).To(Equal("UPPER"))
+		})
+
+		It("should panic when registering twice", func() {
+			Expect(func() {
+				ppath.RegisterFormatter("bosh", func(options ppath.FormatterOptions) (ppath.PathFormatter, error) {
+					return &upperFormatter{}, nil
+				})
+			}).To(Panic())
+		})
+
+		It("should fail with unknown format", func() {
+			_, err := ppath.NewFormatter("unknown-format", nil)
+
+			Expect(err).To(HaveOccurred())
+		})
+
+		Context("with external executable", func() {
+			BeforeEach(func() {
+				dir := GinkgoT().TempDir()
+				script := "#!/bin/sh\ncat\n"
+				Expect(os.WriteFile(filepath.Join(dir, ppath.PluginPrefix+"echo"), []byte(script), 0o755)).To(Succeed())
+				GinkgoT().Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+			})
+
+			It("should give the structured path to the executable", func() {
+				formatter, err := ppath.NewFormatter("echo", ppath.FormatterOptions{"echo.opt": "value"})
+				Expect(err).NotTo(HaveOccurred())
+
+				strpath, err := path.ToString(formatter)
+				Expect(err).NotTo(HaveOccurred())
+
+				var input ppath.PluginInput
+				Expect(json.Unmarshal([]byte(strpath), &input)).To(Succeed())
+				Expect(input.Options).To(Equal(ppath.FormatterOptions{"echo.opt": "value"}))
+				Expect(input.Segments).To(HaveLen(5))
+				Expect(input.Segments[0].Kind).To(Equal("document"))
+				Expect(input.Segments[1]).To(Equal(ppath.PluginSegment{Kind: "key", Key: "top", Index: -1, Line: 1, Column: 1}))
+				Expect(input.Segments[3].Kind).To(Equal("index"))
+				Expect(input.Segments[3].Index).To(Equal(0))
+				Expect(input.Segments[3].Selections).To(Equal(map[string]string{
+					"name":  "myname",
+					"attr1": "val1",
+					"attr2": "val2",
+				}))
+			})
+
+			It("should not look up the executable by path", func() {
+				dir := GinkgoT().TempDir()
+				Expect(os.Mkdir(filepath.Join(dir, ppath.PluginPrefix+"sub"), 0o755)).To(Succeed())
+				script := "#!/bin/sh\ncat\n"
+				Expect(os.WriteFile(filepath.Join(dir, ppath.PluginPrefix+"sub", "echo"), []byte(script), 0o755)).To(Succeed())
+				GinkgoT().Chdir(dir)
+
+				_, err := ppath.NewFormatter("sub/echo", nil)
+
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+})
@@ -0,0 +1,120 @@
+package path
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+
+	dyaml "github.com/gidoichi/yaml-path/domain/yaml"
+)
+
+// PathFormatterTemplate formats the path with user-defined Go text/template.
+// The template defines how to render each segment by the templates named
+// "document", "key", "index" and "selector", and the results are
+// concatenated. For example, the following renders `input.top.first[0]`:
+//
+//	{{define "document"}}input{{end}}
+//	{{define "key"}}.{{.Key}}{{end}}
+//	{{define "index"}}[{{.Index}}]{{end}}
+//
+// "key" and "index" are required. "document" renders nothing if undefined.
+// "selector" renders sequence items selected by the value of NameAttr if it is
+// unique, and "index" is used instead if undefined.
+type PathFormatterTemplate struct {
+	NameAttr string
+	template *template.Template
+}
+
+// TemplateSegment is the data given to the templates of PathFormatterTemplate.
+type TemplateSegment struct {
+	// Kind is "document", "key", "index" or "selector".
+	Kind string
+	// Key is the mapping key for "key" segment.
+	Key string
+	// Index is the index of the sequence item for "index" segment.
+	Index int
+	// NameAttr and Name are the attribute and the value selecting the
+	// sequence item for "selector" segment.
+	NameAttr string
+	Name     string
+	// First and Last report whether the segment is the first or the last
+	// one, excluding the document.
+	First bool
+	Last  bool
+}
+
+var templateFuncs = template.FuncMap{
+	"json":    jsonQuote,
+	"quote":   strconv.Quote,
+	"upper":   strings.ToUpper,
+	"lower":   strings.ToLower,
+	"replace": strings.ReplaceAll,
+}
+
+// NewPathFormatterTemplate parses the template text.
+func NewPathFormatterTemplate(text string, nameAttr string) (formatter *PathFormatterTemplate, err error) {
+	tmpl, err := template.New("path").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parse template: %w", err)
+	}
+	for _, name := range []string{"key", "index"} {
+		if tmpl.Lookup(name) == nil {
+			return nil, fmt.Errorf("template %q is not defined", name)
+		}
+	}
+
+	return &PathFormatterTemplate{
+		NameAttr: nameAttr,
+		template: tmpl,
+	}, nil
+}
+
+func (f *PathFormatterTemplate) ToString(path *Path) (strpath string, err error) {
+	segments, err := path.Segments()
+	if err != nil {
+		return "", fmt.Errorf("get segments: %w", err)
+	}
+
+	last := len(segments) - 1
+	var builder strings.Builder
+	for i, segment := range segments {
+		data := TemplateSegment{
+			First: i == 0 || (i == 1 && segments[0].Kind == dyaml.DocumentSegment),
+			Last:  i == last,
+		}
+		switch segment.Kind {
+		case dyaml.DocumentSegment:
+			data.Kind = "document"
+			data.First = false
+			data.Last = false
+		case dyaml.KeySegment:
+			data.Kind = "key"
+			data.Key = segment.Key
+		case dyaml.IndexSegment, dyaml.SelectorSegment:
+			data.Kind = "index"
+			data.Index = segment.Index
+			if f.NameAttr != "" && f.template.Lookup("selector") != nil {
+				if name := segment.Selection(f.NameAttr); name != "" {
+					data.Kind = "selector"
+					data.NameAttr = f.NameAttr
+					data.Name = name
+				}
+			}
+			if data.Kind == "index" && segment.Index < 0 {
+				return "", fmt.Errorf("unknown index: %s", segment)
+			}
+		default:
+			return "", fmt.Errorf("invalid path: %s", path)
+		}
+
+		if f.template.Lookup(data.Kind) == nil {
+			continue
+		}
+		if err := f.template.ExecuteTemplate(&builder, data.Kind, data); err != nil {
+			return "", fmt.Errorf("execute template: %w", err)
+		}
+	}
+
+	return builder.String(), nil
+}
@@ -0,0 +1,81 @@
+package path_test
+
+import (
+	"bytes"
+
+	dmatcher "github.com/gidoichi/yaml-path/domain/matcher"
+	dyaml "github.com/gidoichi/yaml-path/domain/yaml"
+	ppath "github.com/gidoichi/yaml-path/presentation/path"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PathFormatterTemplate", func() {
+	var path *ppath.Path
+
+	BeforeEach(func() {
+		data := []byte(`top:
+  first:
+    - name: myname
+      attr1: val1
+      attr2: val2
+`)
+		var err error
+		path, err = ppath.NewPath(bytes.NewReader(data), dmatcher.NewNodeMatcherByLineAndCol(5, 14))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	Describe("ToString()", func() {
+		DescribeTable("should render segments with the template",
+			func(text, nameAttr, expected string) {
+				formatter, err := ppath.NewPathFormatterTemplate(text, nameAttr)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(path.ToString(formatter)).To(Equal(expected))
+			},
+			Entry("with document prefix",
+				`{{define "document"}}input{{end}}{{define "key"}}.{{.Key}}{{end}}{{define "index"}}[{{.Index}}]{{end}}`,
+				"", "input.top.first[0].attr2"),
+			Entry("with selector",
+				`{{define "key"}}{{if not .First}}.{{end}}{{.Key}}{{end}}{{define "index"}}[{{.Index}}]{{end}}{{define "selector"}}[{{.NameAttr}}={{json .Name}}]{{end}}`,
+				"name", `top.first[name="myname"].attr2`),
+			Entry("without selector template",
+				`{{define "key"}}/{{upper .Key}}{{end}}{{define "index"}}/{{.Index}}{{end}}`,
+				"name", "/TOP/FIRST/0/ATTR2"),
+		)
+
+		It("should render the last segment", func() {
+			formatter, err := ppath.NewPathFormatterTemplate(`{{define "key"}}{{.Key}}{{if not .Last}}:{{end}}{{end}}{{define "index"}}{{.Index}}:{{end}}`, "")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(path.ToString(formatter)).To(Equal("top:first:0:attr2"))
+		})
+
+		It("should fail with unknown index without selector template", func() {
+			formatter, err := ppath.NewPathFormatterTemplate(`{{define "key"}}.{{.Key}}{{end}}{{define "index"}}[{{.Index}}]{{end}}`, "name")
+			Expect(err).NotTo(HaveOccurred())
+			path = ppath.NewPathFromSegments(dyaml.Segments{
+				dyaml.NewDocumentSegment(),
+				dyaml.NewSelectorSegment("name", "myname"),
+			})
+
+			_, err = path.ToString(formatter)
+
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("NewPathFormatterTemplate()", func() {
+		It("should fail without required templates", func() {
+			_, err := ppath.NewPathFormatterTemplate(`{{define "key"}}.{{.Key}}{{end}}`, "")
+
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should fail with invalid template", func() {
+			_, err := ppath.NewPathFormatterTemplate(`{{define "key"}}`, "")
+
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})